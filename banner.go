@@ -0,0 +1,101 @@
+package njtapi
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// BannerSeverity ranks how disruptive a BannerMessage is.
+type BannerSeverity int
+
+// Banner severities, from least to most disruptive.
+const (
+	SeverityUnknown  BannerSeverity = iota // MSG_TYPE was missing or not recognized
+	SeverityInfo                           // General information, like schedule changes
+	SeverityAdvisory                       // Service advisories, like delays
+	SeverityAlert                          // Urgent alerts, like suspensions or emergencies
+)
+
+func (s BannerSeverity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityAdvisory:
+		return "advisory"
+	case SeverityAlert:
+		return "alert"
+	}
+	return "unknown"
+}
+
+// bannerSeverities maps lowercase MSG_TYPE values to severities.
+var bannerSeverities = map[string]BannerSeverity{
+	"banner":    SeverityInfo,
+	"info":      SeverityInfo,
+	"general":   SeverityInfo,
+	"advisory":  SeverityAdvisory,
+	"delay":     SeverityAdvisory,
+	"delays":    SeverityAdvisory,
+	"alert":     SeverityAlert,
+	"emergency": SeverityAlert,
+}
+
+// A BannerMessage is a station-wide service advisory, as shown across the
+// top of NJTransit's own departure boards.
+type BannerMessage struct {
+	ID          string         // Message ID, if provided
+	Text        string         // Message text
+	Type        string         // Message type as sent by the API, like "banner" or "alert"
+	Severity    BannerSeverity // Severity derived from Type
+	Start       time.Time      // Time the message takes effect, zero if not given
+	End         time.Time      // Time the message expires, zero if not given
+	ParseErrors []error        // Errors encountered while parsing this message
+}
+
+// Active reports whether the message is in effect at t.
+func (b BannerMessage) Active(t time.Time) bool {
+	return (b.Start.IsZero() || !t.Before(b.Start)) && (b.End.IsZero() || t.Before(b.End))
+}
+
+// parseBanners converts the BANNERMSGS block of a schedule. Messages
+// without text are skipped and logged.
+func (c *Client) parseBanners(ctx context.Context, msgs []bannerMsgWire) []BannerMessage {
+	var banners []BannerMessage
+	for _, m := range msgs {
+		text := strings.TrimSpace(m.Text)
+		if text == "" {
+			c.log().LogAttrs(ctx, slog.LevelWarn, "njtapi: skipping banner",
+				slog.String("endpoint", stationDataEndpoint), slog.String("banner_id", strings.TrimSpace(m.ID)),
+				slog.String("type", strings.TrimSpace(m.Type)), slog.String("reason", "no text"))
+			continue
+		}
+		b := BannerMessage{
+			ID:       strings.TrimSpace(m.ID),
+			Text:     text,
+			Type:     strings.TrimSpace(m.Type),
+			Severity: bannerSeverities[strings.ToLower(strings.TrimSpace(m.Type))],
+		}
+		for _, f := range []struct {
+			field string
+			value string
+			dst   *time.Time
+		}{
+			{"MSG_PUBDATE", m.Start, &b.Start},
+			{"MSG_EXPDATE", m.End, &b.End},
+		} {
+			if strings.TrimSpace(f.value) == "" {
+				continue
+			}
+			var err error
+			*f.dst, err = c.parseTime(strings.TrimSpace(f.value))
+			if err != nil {
+				b.ParseErrors = append(b.ParseErrors, &ParseError{Field: f.field, Value: f.value, Err: err})
+			}
+		}
+		c.logParseErrors(ctx, stationDataEndpoint, "", b.ParseErrors)
+		banners = append(banners, b)
+	}
+	return banners
+}
@@ -0,0 +1,64 @@
+package njtapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBannerMessageActive(t *testing.T) {
+	start := time.Date(2024, 7, 23, 18, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	for _, r := range []struct {
+		b    BannerMessage
+		at   time.Time
+		want bool
+	}{
+		{BannerMessage{}, start, true},
+		{BannerMessage{Start: start, End: end}, start.Add(-time.Second), false},
+		{BannerMessage{Start: start, End: end}, start, true},
+		{BannerMessage{Start: start, End: end}, end, false},
+		{BannerMessage{Start: start}, end.Add(24 * time.Hour), true},
+		{BannerMessage{End: end}, start.Add(-24 * time.Hour), true},
+	} {
+		if got := r.b.Active(r.at); got != r.want {
+			t.Errorf("%+v.Active(%v) got %v want %v", r.b, r.at, got, r.want)
+		}
+	}
+}
+
+func TestParseBanners(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Client{location: time.UTC}
+	c.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	got := c.parseBanners(context.Background(), []bannerMsgWire{
+		{Type: " Delay ", Text: "Expect delays", Start: "soon"},
+		{Type: "mystery", Text: "Hello"},
+		{ID: "7", Type: "alert", Text: "  "},
+	})
+	if len(got) != 2 {
+		t.Fatalf("parseBanners() got %d messages want 2: %+v", len(got), got)
+	}
+	if got[0].Severity != SeverityAdvisory || got[0].Type != "Delay" {
+		t.Errorf("parseBanners()[0] got %v %q want advisory Delay", got[0].Severity, got[0].Type)
+	}
+	var pe *ParseError
+	if len(got[0].ParseErrors) != 1 || !errors.As(got[0].ParseErrors[0], &pe) || pe.Field != "MSG_PUBDATE" {
+		t.Errorf("parseBanners()[0] ParseErrors got %v want MSG_PUBDATE error", got[0].ParseErrors)
+	}
+	if got[1].Severity != SeverityUnknown || got[1].Severity.String() != "unknown" {
+		t.Errorf("parseBanners()[1] severity got %v want unknown", got[1].Severity)
+	}
+	want := []map[string]any{
+		{"level": "WARN", "msg": "njtapi: unparseable field", "field": "MSG_PUBDATE"},
+		{"level": "WARN", "msg": "njtapi: skipping banner", "banner_id": "7", "reason": "no text"},
+	}
+	if diff := cmp.Diff(want, logRecords(t, &buf, "level", "msg", "field", "banner_id", "reason")); diff != "" {
+		t.Errorf("parseBanners() logs mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,179 @@
+package njtapi
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// A CacheEntry is a raw API response stored in a Cache.
+type CacheEntry struct {
+	Body        []byte    // Response body
+	ContentType string    // Content-Type header of the response
+	StoredAt    time.Time // Time the response was received
+}
+
+// A Cache stores raw API responses keyed by endpoint and parameters.
+//
+// Implementations must be safe for concurrent use. Caching is best effort, so
+// implementations should treat failures as misses rather than report them.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, e CacheEntry)
+}
+
+// CachePolicy controls which responses are cached and for how long.
+type CachePolicy struct {
+	Cache Cache                    // Where responses are stored
+	TTLs  map[string]time.Duration // How long each endpoint's responses are fresh; endpoints not listed are not cached
+
+	// MaxStale is how long past its TTL an entry may still be served if
+	// refreshing it fails. Zero means stale entries are served indefinitely.
+	MaxStale time.Duration
+}
+
+// DefaultCacheTTLs returns reasonable TTLs for every endpoint, keyed by
+// endpoint name.
+func DefaultCacheTTLs() map[string]time.Duration {
+	return map[string]time.Duration{
+		stationListEndpoint: 24 * time.Hour,
+		stationDataEndpoint: 30 * time.Second,
+		trainMapEndpoint:    15 * time.Second,
+		trainStopsEndpoint:  30 * time.Second,
+		vehicleDataEndpoint: 15 * time.Second,
+	}
+}
+
+// cacheKey identifies a request by endpoint and parameters.
+// Credentials are deliberately not part of the key.
+func cacheKey(endpoint string, params map[string]string) string {
+	q := url.Values{}
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	return endpoint + "?" + q.Encode()
+}
+
+// LRUCache is an in-memory Cache which evicts the least recently used entry
+// once it is full.
+type LRUCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List // Front is most recently used
+	entries map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewLRUCache constructs an LRUCache holding at most size entries.
+func NewLRUCache(size int) *LRUCache {
+	if size < 1 {
+		size = 1
+	}
+	return &LRUCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Get returns the entry stored for key, if any.
+func (c *LRUCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+// Set stores an entry for key, evicting the oldest entry if needed.
+func (c *LRUCache) Set(key string, e CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*lruItem).entry = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruItem{key: key, entry: e})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruItem).key)
+	}
+}
+
+// FileCache is a Cache which stores each entry as a file in a directory,
+// so cached responses survive restarts.
+type FileCache struct {
+	dir string
+}
+
+// fileCacheEntry is the on-disk format of a FileCache entry.
+type fileCacheEntry struct {
+	Key         string    `json:"key"`
+	StoredAt    time.Time `json:"stored_at"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body"`
+}
+
+// NewFileCache constructs a FileCache in dir, creating it if needed.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the entry stored for key, if any.
+func (c *FileCache) Get(key string) (CacheEntry, bool) {
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	var fe fileCacheEntry
+	if err := json.Unmarshal(b, &fe); err != nil || fe.Key != key {
+		return CacheEntry{}, false
+	}
+	return CacheEntry{Body: fe.Body, ContentType: fe.ContentType, StoredAt: fe.StoredAt}, true
+}
+
+// Set stores an entry for key. The file is replaced atomically so readers
+// never see a partial entry.
+func (c *FileCache) Set(key string, e CacheEntry) {
+	b, err := json.Marshal(fileCacheEntry{Key: key, StoredAt: e.StoredAt, ContentType: e.ContentType, Body: e.Body})
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), c.path(key))
+}
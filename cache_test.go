@@ -0,0 +1,239 @@
+package njtapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCacheKey(t *testing.T) {
+	for _, r := range []struct {
+		endpoint string
+		params   map[string]string
+		want     string
+	}{
+		{stationListEndpoint, nil, "getStationListXML?"},
+		{stationDataEndpoint, map[string]string{"station": "NY"}, "getTrainScheduleXML?station=NY"},
+		{trainMapEndpoint, map[string]string{"trainID": "3874", "station": "-"}, "getTrainMapXML?station=-&trainID=3874"},
+	} {
+		if got := cacheKey(r.endpoint, r.params); got != r.want {
+			t.Errorf("cacheKey(%s, %v) got %q want %q", r.endpoint, r.params, got, r.want)
+		}
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", CacheEntry{Body: []byte("a")})
+	c.Set("b", CacheEntry{Body: []byte("b")})
+	if _, ok := c.Get("a"); !ok { // "a" is now the most recently used.
+		t.Error("Get(a) missing")
+	}
+	c.Set("c", CacheEntry{Body: []byte("c")})
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) expected eviction")
+	}
+	for _, k := range []string{"a", "c"} {
+		if e, ok := c.Get(k); !ok || string(e.Body) != k {
+			t.Errorf("Get(%s) got %q, %v", k, e.Body, ok)
+		}
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	c, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCache() error: %v", err)
+	}
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Get(missing) expected miss")
+	}
+
+	want := CacheEntry{Body: []byte("<xml/>"), ContentType: "text/xml", StoredAt: time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC)}
+	c.Set("key", want)
+	got, ok := c.Get("key")
+	if !ok {
+		t.Fatal("Get(key) expected hit")
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Get(key) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	calls := 0
+	fail := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		http.ServeFile(w, r, "testdata/getStationList.xml")
+	}))
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetCache(&CachePolicy{
+		Cache: cache,
+		TTLs:  map[string]time.Duration{stationListEndpoint: time.Hour},
+	})
+
+	for i := 0; i < 2; i++ {
+		got, err := c.StationList(context.Background())
+		if err != nil {
+			t.Fatalf("StationList() unexpected error: %v", err)
+		}
+		if got[0].Stale {
+			t.Error("StationList() unexpectedly stale")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+
+	// Expire the entry and break the server; the old payload is served as stale.
+	key := cacheKey(stationListEndpoint, nil)
+	e, _ := cache.Get(key)
+	e.StoredAt = e.StoredAt.Add(-2 * time.Hour)
+	cache.Set(key, e)
+	fail = true
+
+	got, err := c.StationList(context.Background())
+	if err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+	if len(got) != 6 || !got[0].Stale {
+		t.Errorf("StationList() expected 6 stale stations, got %+v", got)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestClientCacheMaxStale(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	cache.Set(cacheKey(vehicleDataEndpoint, nil), CacheEntry{
+		Body:     []byte("<TRAINS></TRAINS>"),
+		StoredAt: time.Now().Add(-time.Hour),
+	})
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetCache(&CachePolicy{
+		Cache:    cache,
+		TTLs:     DefaultCacheTTLs(),
+		MaxStale: time.Minute,
+	})
+
+	if _, err := c.VehicleData(context.Background()); err == nil {
+		t.Error("VehicleData() expected error for entry older than MaxStale, got none")
+	}
+}
+
+func TestClientCacheDecodeFailure(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			_, _ = w.Write([]byte("<STATIONS><STATION>"))
+			return
+		}
+		http.ServeFile(w, r, "testdata/getStationList.xml")
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetCache(&CachePolicy{
+		Cache: NewLRUCache(10),
+		TTLs:  map[string]time.Duration{stationListEndpoint: time.Hour},
+	})
+
+	if _, err := c.StationList(context.Background()); err == nil {
+		t.Fatal("StationList() expected error for malformed payload, got none")
+	}
+	// The malformed payload was not cached, so the next call refetches.
+	if got, err := c.StationList(context.Background()); err != nil || len(got) != 6 {
+		t.Errorf("StationList() got %d stations, %v want 6", len(got), err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestClientCacheStaleOnDecodeFailure(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls > 1 {
+			_, _ = w.Write([]byte("<STATIONS><STATION><STATION_2CHAR>NY</STATION_2CHAR>"))
+			return
+		}
+		http.ServeFile(w, r, "testdata/getStationList.xml")
+	}))
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetCache(&CachePolicy{
+		Cache: cache,
+		TTLs:  map[string]time.Duration{stationListEndpoint: time.Hour},
+	})
+
+	if _, err := c.StationList(context.Background()); err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+
+	// Expire the entry; the truncated refresh falls back to the old payload.
+	key := cacheKey(stationListEndpoint, nil)
+	e, _ := cache.Get(key)
+	e.StoredAt = e.StoredAt.Add(-2 * time.Hour)
+	cache.Set(key, e)
+
+	got, err := c.StationList(context.Background())
+	if err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+	if len(got) != 6 || !got[0].Stale {
+		t.Errorf("StationList() expected 6 stale stations, got %+v", got)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestClientCacheContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Sniffing would take this for XML, so decoding depends on the header.
+		_, _ = w.Write([]byte(`null`))
+	}))
+	defer ts.Close()
+
+	cache := NewLRUCache(10)
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetCache(&CachePolicy{
+		Cache: cache,
+		TTLs:  map[string]time.Duration{stationListEndpoint: time.Hour},
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.StationList(context.Background()); err != nil {
+			t.Fatalf("StationList() call %d unexpected error: %v", i, err)
+		}
+	}
+	if e, _ := cache.Get(cacheKey(stationListEndpoint, nil)); e.ContentType != "application/json" {
+		t.Errorf("cached ContentType got %q want application/json", e.ContentType)
+	}
+}
@@ -12,9 +12,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"reflect"
 	"time"
 )
 
@@ -22,9 +23,23 @@ import (
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
-	username   string
-	password   string
+	creds      CredentialsProvider
 	location   *time.Location
+	retry      *RetryPolicy
+	limiter    Limiter
+	cache      *CachePolicy
+	coalesce   bool
+	flights    flightGroup
+	authMode   AuthMode
+	tokens     tokenCache
+	format     Format
+	middleware []Middleware
+	observer   Observer
+	logger     *slog.Logger
+	userAgent  string
+
+	requestTimeout time.Duration // Bound on each HTTP request, if non-zero
+	callTimeout    time.Duration // Bound on each public method call, if non-zero
 }
 
 // ErrUnexpectedStatus is returned when the API returns a non-2xx HTTP status code.
@@ -34,6 +49,8 @@ var ErrUnexpectedStatus = errors.New("unexpected HTTP status")
 type APIError struct {
 	StatusCode int
 	Body       string
+
+	retryAfter time.Duration // Parsed Retry-After header, if any
 }
 
 func (e *APIError) Error() string {
@@ -51,37 +68,95 @@ func (e *APIError) Unwrap() error {
 //
 // baseURL: The root URL that the API is exposed on.
 // username / password: Authentication credentials for calling the API.
+//
+// It is equivalent to New(baseURL, WithBasicAuth(username, password)).
 func NewClient(baseURL, username, password string) *Client {
-	return NewClientWithLocation(baseURL, username, password, defaultLocation())
+	return New(baseURL, WithBasicAuth(username, password))
 }
 
 // NewClientWithLocation constructs a new client with a custom timezone location.
 // If the provided location is nil, it falls back to UTC.
+//
+// It is equivalent to New with WithBasicAuth and WithLocation.
 func NewClientWithLocation(baseURL, username, password string, loc *time.Location) *Client {
-	if loc == nil {
-		loc = time.UTC
-	}
-	return &Client{
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-		baseURL:    baseURL,
-		username:   username,
-		password:   password,
-		location:   loc,
-	}
+	return New(baseURL, WithBasicAuth(username, password), WithLocation(loc))
 }
 
 // NewCustomClient uses the supplied `http.Client` when talking to the API.
 // This can be useful if you need to supply a custom timeout, proxy server, etc.
 //
-// See `NewClient` for a description of the rest of the parameters.
+// See `NewClient` for a description of the rest of the parameters. Use New
+// with WithHTTPClient and WithLocation to also choose a timezone.
 func NewCustomClient(c *http.Client, baseURL, username, password string) *Client {
-	return &Client{
-		httpClient: c,
-		baseURL:    baseURL,
-		username:   username,
-		password:   password,
-		location:   defaultLocation(),
-	}
+	return New(baseURL, WithHTTPClient(c), WithBasicAuth(username, password))
+}
+
+// SetCredentials replaces the credentials supplied at construction with a
+// provider consulted before every request.
+//
+// It should be called before the client is used.
+func (c *Client) SetCredentials(p CredentialsProvider) {
+	c.creds = p
+}
+
+// SetRetryPolicy configures how failed requests are retried.
+// Passing nil disables retries, which is the default.
+//
+// It should be called before the client is used.
+func (c *Client) SetRetryPolicy(p *RetryPolicy) {
+	c.retry = p
+}
+
+// SetLimiter configures a Limiter consulted before every request.
+// Passing nil disables limiting, which is the default.
+//
+// It should be called before the client is used.
+func (c *Client) SetLimiter(l Limiter) {
+	c.limiter = l
+}
+
+// SetCache configures caching of API responses.
+// Passing nil disables caching, which is the default.
+//
+// It should be called before the client is used.
+func (c *Client) SetCache(p *CachePolicy) {
+	c.cache = p
+}
+
+// SetCoalescing controls whether concurrent identical requests (same
+// endpoint and parameters) share a single HTTP round-trip and parse.
+// It is disabled by default.
+//
+// When enabled, callers may receive the same *Station, *Train or slice
+// and must treat the results as read-only.
+//
+// It should be called before the client is used.
+func (c *Client) SetCoalescing(enabled bool) {
+	c.coalesce = enabled
+}
+
+// SetAuthMode selects how the client authenticates with the API.
+// The default is AuthQuery. The base URL must point at the matching API.
+//
+// It should be called before the client is used.
+func (c *Client) SetAuthMode(m AuthMode) {
+	c.authMode = m
+}
+
+// SetFormat selects how responses are decoded. The default, FormatAuto,
+// decodes based on each response's Content-Type.
+//
+// It should be called before the client is used.
+func (c *Client) SetFormat(f Format) {
+	c.format = f
+}
+
+// SetObserver configures an Observer notified of every public method call,
+// e.g. to record tracing spans and metrics. Passing nil disables it.
+//
+// It should be called before the client is used.
+func (c *Client) SetObserver(o Observer) {
+	c.observer = o
 }
 
 func defaultLocation() *time.Location {
@@ -92,17 +167,113 @@ func defaultLocation() *time.Location {
 	return loc
 }
 
-// fetch retrieves data from an API endpoint.
-func (c *Client) fetch(ctx context.Context, endpoint string, params map[string]string) ([]byte, error) {
-	u, err := url.Parse(c.baseURL)
+// response is the raw result of calling an API endpoint.
+type response struct {
+	body        []byte
+	contentType string // Content-Type header
+	stale       bool   // Served from the cache after a failed refresh
+}
+
+// load retrieves data from an API endpoint and decodes it into v,
+// consulting the cache if one is configured. Only responses which decode
+// are cached, so a malformed payload is never served from the cache; if a
+// refresh fails or doesn't decode, the last good payload is served as stale.
+func (c *Client) load(ctx context.Context, endpoint string, params map[string]string, v any) (*response, error) {
+	var ttl time.Duration
+	if c.cache != nil {
+		ttl = c.cache.TTLs[endpoint]
+	}
+	if ttl <= 0 {
+		resp, err := c.fetch(ctx, endpoint, params)
+		if err != nil {
+			return nil, err
+		}
+		return resp, c.decode(resp, v)
+	}
+
+	key := cacheKey(endpoint, params)
+	entry, cached := c.cache.Cache.Get(key)
+	if cached && time.Since(entry.StoredAt) < ttl {
+		c.log().LogAttrs(ctx, slog.LevelDebug, "njtapi: cache hit",
+			slog.String("endpoint", endpoint), slog.Duration("age", time.Since(entry.StoredAt)))
+		resp := &response{body: entry.Body, contentType: entry.ContentType}
+		return resp, c.decode(resp, v)
+	}
+
+	resp, err := c.fetch(ctx, endpoint, params)
+	if err == nil {
+		if err = c.decode(resp, v); err == nil {
+			c.cache.Cache.Set(key, CacheEntry{Body: resp.body, ContentType: resp.contentType, StoredAt: time.Now()})
+			return resp, nil
+		}
+	}
+
+	// The refresh failed or returned a payload which doesn't decode. Fall
+	// back to the last good payload, unless the caller gave up.
+	maxAge := ttl + c.cache.MaxStale
+	if cached && ctx.Err() == nil && (c.cache.MaxStale == 0 || time.Since(entry.StoredAt) < maxAge) {
+		c.log().LogAttrs(ctx, slog.LevelWarn, "njtapi: serving stale response",
+			slog.String("endpoint", endpoint), slog.Duration("age", time.Since(entry.StoredAt)), slog.Any("error", err))
+		// Clear anything a partial decode of the refresh left behind.
+		reflect.ValueOf(v).Elem().SetZero()
+		resp := &response{body: entry.Body, contentType: entry.ContentType, stale: true}
+		return resp, c.decode(resp, v)
+	}
+	return nil, err
+}
+
+// credentials returns the credentials to use for the next request.
+func (c *Client) credentials(ctx context.Context) (Credentials, error) {
+	if c.creds == nil {
+		return Credentials{}, ErrMissingCredentials
+	}
+	return c.creds.Credentials(ctx)
+}
+
+// fetch retrieves data from an API endpoint over the network.
+func (c *Client) fetch(ctx context.Context, endpoint string, params map[string]string) (*response, error) {
+	call := func(ctx context.Context) (*response, error) {
+		if c.authMode == AuthToken {
+			// Token requests are gated individually as they are made.
+			return c.postWithToken(ctx, endpoint, params)
+		}
+		if err := c.wait(ctx, endpoint); err != nil {
+			return nil, err
+		}
+		return c.get(ctx, endpoint, params)
+	}
+	if c.retry == nil {
+		return call(ctx)
+	}
+	return c.retry.do(ctx, call)
+}
+
+// wait blocks until the client's Limiter, if any, lets a request to
+// endpoint through.
+func (c *Client) wait(ctx context.Context, endpoint string) error {
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.Wait(ctx, endpoint)
+}
+
+// get calls a legacy endpoint, passing credentials in the query string.
+func (c *Client) get(ctx context.Context, endpoint string, params map[string]string) (*response, error) {
+	creds, err := c.credentials(ctx)
 	if err != nil {
 		return nil, err
 	}
+	s := secrets{creds.Password}
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, s.redactError(err)
+	}
 
 	u = u.JoinPath(endpoint)
 	q := u.Query()
-	q.Set("username", c.username)
-	q.Set("password", c.password)
+	q.Set("username", creds.Username)
+	q.Set("password", creds.Password)
 	for k, v := range params {
 		q.Set(k, v)
 	}
@@ -110,29 +281,56 @@ func (c *Client) fetch(ctx context.Context, endpoint string, params map[string]s
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, s.redactError(err)
 	}
-	resp, err := c.httpClient.Do(req.WithContext(ctx))
-	if err != nil {
-		return nil, err
+	return c.do(ctx, &Request{Endpoint: endpoint, Params: params, HTTP: req}, s)
+}
+
+// do sends a single request through the middleware chain and returns the
+// response. Any of the supplied secrets are scrubbed from returned errors.
+func (c *Client) do(ctx context.Context, req *Request, s secrets) (*response, error) {
+	if c.userAgent != "" {
+		req.HTTP.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
 	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+
+	start := time.Now()
+	resp, err := c.roundTrip(ctx, req)
 	if err != nil {
+		err = s.redactError(err)
+		c.log().LogAttrs(ctx, slog.LevelWarn, "njtapi: request failed",
+			slog.String("endpoint", req.Endpoint), slog.String("url", s.redact(req.RedactedURL())),
+			slog.Duration("duration", time.Since(start)), slog.Any("error", err))
 		return nil, err
 	}
+	recordResponse(ctx, resp)
+	body := resp.Body
+
+	level := slog.LevelDebug
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		level = slog.LevelWarn
+	}
+	c.log().LogAttrs(ctx, level, "njtapi: request",
+		slog.String("endpoint", req.Endpoint), slog.String("url", s.redact(req.RedactedURL())),
+		slog.Int("status", resp.StatusCode), slog.Int("bytes", len(body)),
+		slog.Duration("duration", time.Since(start)))
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		const maxBodyLen = 1024
-		errBody := string(body)
+		errBody := s.redact(string(body))
 		if len(errBody) > maxBodyLen {
 			errBody = errBody[:maxBodyLen] + "..."
 		}
 		return nil, &APIError{
 			StatusCode: resp.StatusCode,
 			Body:       errBody,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
 		}
 	}
 
-	return body, nil
+	return &response{body: body, contentType: resp.Header.Get("Content-Type")}, nil
 }
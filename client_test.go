@@ -45,12 +45,12 @@ func TestFetch(t *testing.T) {
 	defer srv.Close()
 
 	c := NewClient(srv.URL, "user", "pass")
-	body, err := c.fetch(context.Background(), "testEndpoint", nil)
+	resp, err := c.fetch(context.Background(), "testEndpoint", nil)
 	if err != nil {
 		t.Fatalf("fetch() error = %v", err)
 	}
-	if string(body) != "<response>ok</response>" {
-		t.Errorf("fetch() = %q, want %q", string(body), "<response>ok</response>")
+	if string(resp.body) != "<response>ok</response>" {
+		t.Errorf("fetch() = %q, want %q", string(resp.body), "<response>ok</response>")
 	}
 }
 
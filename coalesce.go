@@ -0,0 +1,104 @@
+package njtapi
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup deduplicates concurrent calls which share a key, similar to
+// golang.org/x/sync/singleflight, except that each caller can give up
+// independently. The shared call is only cancelled once every caller has.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// A flight is an in-progress call shared by one or more callers.
+type flight struct {
+	done    chan struct{}
+	val     any
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do calls fn, or joins an identical call already in progress.
+//
+// fn runs with a context that carries ctx's values but not its deadline or
+// cancellation, since it is shared with other callers.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = map[string]*flight{}
+	}
+	f, ok := g.flights[key]
+	if !ok {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.flights[key] = f
+		go func() {
+			f.val, f.err = fn(fctx)
+			cancel()
+			g.forget(key, f)
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// forget removes f from the group so later callers start a new call.
+func (g *flightGroup) forget(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
+
+// flightResult is the value of a coalesced call, along with the HTTP
+// details every caller reports to its Observer.
+type flightResult struct {
+	val   any
+	stats *callStats
+}
+
+// coalesce runs fn through the client's flightGroup when coalescing is
+// enabled, so concurrent identical requests share one fetch and parse.
+func coalesce[T any](ctx context.Context, c *Client, key string, fn func(context.Context) (T, error)) (T, error) {
+	if !c.coalesce {
+		return fn(ctx)
+	}
+	v, err := c.flights.do(ctx, key, func(ctx context.Context) (any, error) {
+		// The shared call records its responses separately, since it
+		// runs on behalf of every caller rather than just the first.
+		stats := &callStats{}
+		v, err := fn(context.WithValue(ctx, callStatsKey{}, stats))
+		return flightResult{val: v, stats: stats}, err
+	})
+	res, _ := v.(flightResult)
+	if res.stats != nil {
+		addCallStats(ctx, res.stats)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return res.val.(T), nil
+}
@@ -0,0 +1,132 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForWaiters blocks until n callers have joined the flight for key.
+func waitForWaiters(t *testing.T, c *Client, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		c.flights.mu.Lock()
+		got := 0
+		if f := c.flights.flights[key]; f != nil {
+			got = f.waiters
+		}
+		c.flights.mu.Unlock()
+		if got >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d callers to join %q, have %d", n, key, got)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCoalesceStationData(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		http.ServeFile(w, r, "testdata/getTrainSchedule1.xml")
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetCoalescing(true)
+
+	const n = 50
+	results := make([]*Station, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = c.StationData(context.Background(), "SE")
+		}(i)
+	}
+
+	// One caller gives up early without affecting the others.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := c.StationData(ctx, "SE")
+		cancelled <- err
+	}()
+	waitForWaiters(t, c, cacheKey(stationDataEndpoint, map[string]string{"station": "SE"}), n+1)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled StationData() got %v want context.Canceled", err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("StationData() #%d unexpected error: %v", i, errs[i])
+		}
+		if results[i] != results[0] {
+			t.Errorf("StationData() #%d did not share the parsed result", i)
+		}
+	}
+}
+
+func TestCoalesceAllCallersCancel(t *testing.T) {
+	aborted := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(aborted)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetCoalescing(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.VehicleData(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("VehicleData() got %v want context.DeadlineExceeded", err)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Error("shared request was not cancelled after every caller gave up")
+	}
+}
+
+func TestCoalesceDistinctKeys(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.ServeFile(w, r, "testdata/getTrainSchedule1.xml")
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetCoalescing(true)
+
+	for _, s := range []string{"SE", "NY", "SE"} {
+		if _, err := c.StationData(context.Background(), s); err != nil {
+			t.Fatalf("StationData(%s) unexpected error: %v", s, err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("expected sequential calls not to be coalesced, got %d calls", calls)
+	}
+}
@@ -0,0 +1,46 @@
+package njtapi
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseColor(t *testing.T) {
+	for _, r := range []struct {
+		in      string
+		want    *Color
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: " \n ", want: nil},
+		{in: "#F7505E", want: &Color{0xf7, 0x50, 0x5e}},
+		{in: "#f7505e", want: &Color{0xf7, 0x50, 0x5e}},
+		{in: "#0f0", want: &Color{0x00, 0xff, 0x00}},
+		{in: "CornflowerBlue", want: &Color{0x64, 0x95, 0xed}},
+		{in: " red\n", want: &Color{0xff, 0x00, 0x00}},
+		{in: "#12345", wantErr: true},
+		{in: "#GGGGGG", wantErr: true},
+		{in: "blurple", wantErr: true},
+	} {
+		got, err := parseColor(r.in)
+		if (err != nil) != r.wantErr {
+			t.Errorf("parseColor(%q) error got %v want error %v", r.in, err, r.wantErr)
+		}
+		if diff := cmp.Diff(r.want, got); diff != "" {
+			t.Errorf("parseColor(%q) mismatch (-want +got):\n%s", r.in, diff)
+		}
+	}
+}
+
+func TestColor(t *testing.T) {
+	c := Color{0xf7, 0x50, 0x5e}
+	if got := c.String(); got != "#f7505e" {
+		t.Errorf("String() got %q want #f7505e", got)
+	}
+	want := color.RGBA{0xf7, 0x50, 0x5e, 0xff}
+	if got := color.RGBAModel.Convert(c); got != want {
+		t.Errorf("RGBAModel.Convert() got %v want %v", got, want)
+	}
+}
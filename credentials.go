@@ -0,0 +1,110 @@
+package njtapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrMissingCredentials is returned when a CredentialsProvider has no
+// credentials to offer.
+var ErrMissingCredentials = errors.New("missing credentials")
+
+// Credentials authenticate the client with the NJTransit API.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// A CredentialsProvider supplies credentials for each request.
+//
+// Providers are consulted before every request (and every token refresh in
+// AuthToken mode), so rotated credentials are picked up without rebuilding
+// the client. Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// StaticCredentials is a CredentialsProvider which always returns the same
+// username and password.
+type StaticCredentials Credentials
+
+// Credentials returns the static credentials.
+func (s StaticCredentials) Credentials(context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// EnvCredentials is a CredentialsProvider which reads credentials from
+// environment variables on every request.
+type EnvCredentials struct {
+	UsernameVar string // Defaults to NJT_USERNAME
+	PasswordVar string // Defaults to NJT_PASSWORD
+}
+
+// Credentials reads the username and password from the environment.
+func (e EnvCredentials) Credentials(context.Context) (Credentials, error) {
+	uVar := orDefault(e.UsernameVar, "NJT_USERNAME")
+	pVar := orDefault(e.PasswordVar, "NJT_PASSWORD")
+	creds := Credentials{Username: os.Getenv(uVar), Password: os.Getenv(pVar)}
+	if creds.Username == "" || creds.Password == "" {
+		return Credentials{}, fmt.Errorf("%w: %s and %s must be set", ErrMissingCredentials, uVar, pVar)
+	}
+	return creds, nil
+}
+
+// FileCredentials is a CredentialsProvider which reads credentials from a
+// JSON file like {"username": "...", "password": "..."}.
+//
+// The file is re-read whenever its modification time or size changes, so
+// credentials rotated by a secrets manager are picked up automatically.
+type FileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	creds   Credentials
+	modTime time.Time
+	size    int64
+}
+
+// NewFileCredentials constructs a FileCredentials reading from path.
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{path: path}
+}
+
+// Credentials returns the credentials in the file, reloading it if it
+// has changed since it was last read.
+func (f *FileCredentials) Credentials(context.Context) (Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if f.creds.Username != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.creds, nil
+	}
+
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	data := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return Credentials{}, fmt.Errorf("reading credentials file %s: %w", f.path, err)
+	}
+	if data.Username == "" || data.Password == "" {
+		return Credentials{}, fmt.Errorf("%w: %s has no username or password", ErrMissingCredentials, f.path)
+	}
+	f.creds = Credentials(data)
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return f.creds, nil
+}
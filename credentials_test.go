@@ -0,0 +1,113 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaticCredentials(t *testing.T) {
+	want := Credentials{Username: "user", Password: "pass"}
+	got, err := StaticCredentials(want).Credentials(context.Background())
+	if err != nil || got != want {
+		t.Errorf("Credentials() got %v, %v want %v", got, err, want)
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("NJT_USERNAME", "")
+	t.Setenv("NJT_PASSWORD", "")
+	if _, err := (EnvCredentials{}).Credentials(context.Background()); !errors.Is(err, ErrMissingCredentials) {
+		t.Errorf("Credentials() got %v want ErrMissingCredentials", err)
+	}
+
+	t.Setenv("NJT_USERNAME", "user")
+	t.Setenv("NJT_PASSWORD", "pass")
+	t.Setenv("CUSTOM_PASSWORD", "other")
+	for _, r := range []struct {
+		env  EnvCredentials
+		want Credentials
+	}{
+		{EnvCredentials{}, Credentials{"user", "pass"}},
+		{EnvCredentials{PasswordVar: "CUSTOM_PASSWORD"}, Credentials{"user", "other"}},
+	} {
+		got, err := r.env.Credentials(context.Background())
+		if err != nil || got != r.want {
+			t.Errorf("%+v.Credentials() got %v, %v want %v", r.env, got, err, r.want)
+		}
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "creds.json")
+	f := NewFileCredentials(path)
+	ctx := context.Background()
+
+	if _, err := f.Credentials(ctx); err == nil {
+		t.Error("Credentials() expected error for missing file, got none")
+	}
+
+	write := func(s string, mod time.Time) {
+		if err := os.WriteFile(path, []byte(s), 0o600); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatalf("Chtimes() error: %v", err)
+		}
+	}
+
+	mod := time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC)
+	write(`{"username": "user", "password": "old"}`, mod)
+	if got, err := f.Credentials(ctx); err != nil || got.Password != "old" {
+		t.Errorf("Credentials() got %v, %v want password old", got, err)
+	}
+
+	write(`{"username": "user", "password": "new"}`, mod.Add(time.Minute))
+	if got, err := f.Credentials(ctx); err != nil || got.Password != "new" {
+		t.Errorf("Credentials() after rotation got %v, %v want password new", got, err)
+	}
+
+	write(`{"username": "user"}`, mod.Add(2*time.Minute))
+	if _, err := f.Credentials(ctx); !errors.Is(err, ErrMissingCredentials) {
+		t.Errorf("Credentials() got %v want ErrMissingCredentials", err)
+	}
+}
+
+// rotatingCredentials hands out a new password on every call.
+type rotatingCredentials struct{ n int }
+
+func (r *rotatingCredentials) Credentials(context.Context) (Credentials, error) {
+	r.n++
+	return Credentials{Username: "user", Password: strings.Repeat("x", r.n)}, nil
+}
+
+func TestClientCredentialsProvider(t *testing.T) {
+	var passwords []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		passwords = append(passwords, r.URL.Query().Get("password"))
+		http.ServeFile(w, r, "testdata/getStationList.xml")
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetCredentials(&rotatingCredentials{})
+	for i := 0; i < 2; i++ {
+		if _, err := c.StationList(context.Background()); err != nil {
+			t.Fatalf("StationList() unexpected error: %v", err)
+		}
+	}
+	if len(passwords) != 2 || passwords[0] != "x" || passwords[1] != "xx" {
+		t.Errorf("expected the provider to be consulted per request, got passwords %q", passwords)
+	}
+
+	c.SetCredentials(EnvCredentials{UsernameVar: "NJTAPI_TEST_UNSET", PasswordVar: "NJTAPI_TEST_UNSET"})
+	if _, err := c.StationList(context.Background()); !errors.Is(err, ErrMissingCredentials) {
+		t.Errorf("StationList() got %v want ErrMissingCredentials", err)
+	}
+}
@@ -0,0 +1,204 @@
+//go:build ignore
+
+// import_gtfs fills in accessibility in stations.json from the stops.txt
+// file of NJ Transit's rail GTFS feed.
+//
+//	go run data/import_gtfs.go -version 2026.11.0 path/to/stops.txt
+//
+// Stops are matched to stations by code, then name, then location. Stops
+// which match no station are reported and otherwise ignored.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bamnet/njtapi"
+)
+
+// maxDistance is how far, in meters, a stop may be from the station it is
+// matched to by location.
+const maxDistance = 250
+
+// station is an entry in stations.json, with fields in file order.
+type station struct {
+	Code       string   `json:"code"`
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Lat        float64  `json:"lat"`
+	Lng        float64  `json:"lng"`
+	Lines      []string `json:"lines"`
+	Accessible *bool    `json:"accessible,omitempty"`
+}
+
+type dataset struct {
+	Version  string    `json:"version"`
+	Note     string    `json:"note"`
+	Stations []station `json:"stations"`
+}
+
+func main() {
+	file := flag.String("data", "data/stations.json", "station dataset to update")
+	version := flag.String("version", "", "new dataset version")
+	flag.Parse()
+	if flag.NArg() != 1 || *version == "" {
+		log.Fatal("usage: go run data/import_gtfs.go -version VERSION stops.txt")
+	}
+
+	b, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var data dataset
+	if err := json.Unmarshal(b, &data); err != nil {
+		log.Fatal(err)
+	}
+	byCode := map[string]*station{}
+	for i := range data.Stations {
+		byCode[data.Stations[i].Code] = &data.Stations[i]
+	}
+
+	stops, err := readStops(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, stop := range stops {
+		s := byCode[match(stop)]
+		if s == nil {
+			log.Printf("no station for stop %s %q", stop["stop_id"], stop["stop_name"])
+			continue
+		}
+		// 0 or empty means unknown, so any existing value is kept.
+		switch stop["wheelchair_boarding"] {
+		case "1":
+			s.Accessible = boolPtr(true)
+		case "2":
+			s.Accessible = boolPtr(false)
+		}
+	}
+
+	data.Version = *version
+	data.Note = note(data.Stations)
+	if err := os.WriteFile(*file, encode(data), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readStops returns each row of a GTFS stops.txt file, keyed by column.
+func readStops(file string) ([]map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s is empty", file)
+	}
+	header := rows[0]
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	var stops []map[string]string
+	for _, row := range rows[1:] {
+		stop := map[string]string{}
+		for i, col := range header {
+			if i < len(row) {
+				stop[col] = strings.TrimSpace(row[i])
+			}
+		}
+		stops = append(stops, stop)
+	}
+	return stops, nil
+}
+
+// match returns the code of the station for a stop, or "" if none matches.
+func match(stop map[string]string) string {
+	for _, key := range []string{stop["stop_code"], stop["stop_name"]} {
+		if info, ok := njtapi.LookupStation(key); ok && key != "" {
+			return info.Code
+		}
+	}
+	lat, errLat := strconv.ParseFloat(stop["stop_lat"], 64)
+	lng, errLng := strconv.ParseFloat(stop["stop_lon"], 64)
+	if errLat != nil || errLng != nil {
+		return ""
+	}
+	p := njtapi.LatLng{Lat: lat, Lng: lng}
+	if near := njtapi.NearestStations(p, 1); len(near) == 1 && p.Distance(near[0].LatLng) <= maxDistance {
+		return near[0].Code
+	}
+	return ""
+}
+
+// note describes which stations are still missing GTFS data.
+func note(stations []station) string {
+	var noAccess []string
+	for _, s := range stations {
+		if s.Accessible == nil {
+			noAccess = append(noAccess, s.Code)
+		}
+	}
+	return fmt.Sprintf("Stations missing accessibility from the stops.txt of NJ Transit's rail GTFS feed, which data/import_gtfs.go imports: %s.",
+		codes(noAccess, len(stations)))
+}
+
+func codes(missing []string, total int) string {
+	switch len(missing) {
+	case 0:
+		return "none"
+	case total:
+		return "all stations"
+	}
+	return strings.Join(missing, " ")
+}
+
+// encode formats the dataset with one station per line.
+func encode(data dataset) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "{\n  \"version\": %s,\n  \"note\": %s,\n  \"stations\": [\n", mustMarshal(data.Version), mustMarshal(data.Note))
+	for i, s := range data.Stations {
+		fields := []string{
+			`"code": ` + mustMarshal(s.Code),
+			`"name": ` + mustMarshal(s.Name),
+		}
+		if len(s.Aliases) > 0 {
+			fields = append(fields, `"aliases": `+strings.ReplaceAll(mustMarshal(s.Aliases), `","`, `", "`))
+		}
+		fields = append(fields,
+			`"lat": `+mustMarshal(s.Lat),
+			`"lng": `+mustMarshal(s.Lng),
+			`"lines": `+strings.ReplaceAll(mustMarshal(s.Lines), `","`, `", "`),
+		)
+		if s.Accessible != nil {
+			fields = append(fields, `"accessible": `+mustMarshal(*s.Accessible))
+		}
+		sep := ","
+		if i == len(data.Stations)-1 {
+			sep = ""
+		}
+		fmt.Fprintf(&b, "    {%s}%s\n", strings.Join(fields, ", "), sep)
+	}
+	b.WriteString("  ]\n}\n")
+	return b.Bytes()
+}
+
+func mustMarshal(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(b)
+}
+
+func boolPtr(b bool) *bool { return &b }
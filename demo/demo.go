@@ -18,7 +18,7 @@ var (
 func main() {
 	flag.Parse()
 
-	c := njtapi.NewClient(*baseURL, *username, *password)
+	c := njtapi.New(*baseURL, njtapi.WithBasicAuth(*username, *password))
 	trains, err := c.VehicleData(context.Background())
 	if err != nil {
 		log.Fatalf("VehicleData() error: %v", err)
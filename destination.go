@@ -0,0 +1,73 @@
+package njtapi
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// DestinationFlags are service details encoded in a destination string.
+type DestinationFlags uint8
+
+// Destination flags.
+const (
+	DestinationAirport     DestinationFlags = 1 << iota // Serves Newark Liberty International Airport, shown as ✈
+	DestinationViaSecaucus                              // Runs via Secaucus Junction, shown as "-SEC"
+	DestinationExpress                                  // Express service, shown as "EXP" or "Express"
+)
+
+// Has reports whether all of flag are set.
+func (f DestinationFlags) Has(flag DestinationFlags) bool {
+	return f&flag == flag
+}
+
+// String lists the flags which are set, like "Airport|ViaSecaucus".
+func (f DestinationFlags) String() string {
+	var names []string
+	for _, n := range []struct {
+		flag DestinationFlags
+		name string
+	}{
+		{DestinationAirport, "Airport"},
+		{DestinationViaSecaucus, "ViaSecaucus"},
+		{DestinationExpress, "Express"},
+	} {
+		if f.Has(n.flag) {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+var (
+	destinationSecaucusRe = regexp.MustCompile(`(?i)\s*-?\s*\bSEC\b`)
+	destinationExpressRe  = regexp.MustCompile(`(?i)\s*-?\s*\b(?:EXP|EXPRESS)\b\.?`)
+)
+
+// normalizeDestination cleans up a destination from the API, which can
+// contain HTML entities (sometimes escaped twice, like "Trenton &#9992"),
+// stray whitespace and service markers, and returns the flags the markers
+// represent.
+func normalizeDestination(raw string) (string, DestinationFlags) {
+	s := raw
+	for i := 0; i < 2 && strings.Contains(s, "&"); i++ {
+		s = html.UnescapeString(s)
+	}
+
+	var flags DestinationFlags
+	if strings.ContainsRune(s, '✈') {
+		flags |= DestinationAirport
+		s = strings.NewReplacer("✈", " ", "️", "").Replace(s)
+	}
+	if destinationSecaucusRe.MatchString(s) {
+		flags |= DestinationViaSecaucus
+		s = destinationSecaucusRe.ReplaceAllString(s, "")
+	}
+	if destinationExpressRe.MatchString(s) {
+		flags |= DestinationExpress
+		s = destinationExpressRe.ReplaceAllString(s, "")
+	}
+
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.Trim(s, " -"), flags
+}
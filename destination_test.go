@@ -0,0 +1,47 @@
+package njtapi
+
+import "testing"
+
+func TestNormalizeDestination(t *testing.T) {
+	for _, r := range []struct {
+		raw       string
+		want      string
+		wantFlags DestinationFlags
+	}{
+		{"Dover", "Dover", 0},
+		{"  Hackettstown \n", "Hackettstown", 0},
+		{"Trenton &#9992", "Trenton", DestinationAirport},
+		{"Trenton &amp;#9992", "Trenton", DestinationAirport},
+		{"Trenton &#9992;", "Trenton", DestinationAirport},
+		{"Trenton ✈", "Trenton", DestinationAirport},
+		{"Trenton ✈️", "Trenton", DestinationAirport},
+		{"Long Branch-BH &#9992", "Long Branch-BH", DestinationAirport},
+		{"Long Branch-BH -SEC &#9992", "Long Branch-BH", DestinationAirport | DestinationViaSecaucus},
+		{"Suffern -SEC", "Suffern", DestinationViaSecaucus},
+		{"Port Jervis-SEC", "Port Jervis", DestinationViaSecaucus},
+		{"Secaucus", "Secaucus", 0},
+		{"Dover EXP", "Dover", DestinationExpress},
+		{"Bay Head -Express", "Bay Head", DestinationExpress},
+		{"Experience Hill", "Experience Hill", 0},
+		{"Summit &amp; Dover", "Summit & Dover", 0},
+		{"", "", 0},
+	} {
+		got, flags := normalizeDestination(r.raw)
+		if got != r.want || flags != r.wantFlags {
+			t.Errorf("normalizeDestination(%q) got %q, %v want %q, %v", r.raw, got, flags, r.want, r.wantFlags)
+		}
+	}
+}
+
+func TestDestinationFlags(t *testing.T) {
+	f := DestinationAirport | DestinationExpress
+	if !f.Has(DestinationAirport) || f.Has(DestinationViaSecaucus) || f.Has(DestinationAirport|DestinationViaSecaucus) {
+		t.Errorf("Has() gave unexpected results for %v", f)
+	}
+	if got := f.String(); got != "Airport|Express" {
+		t.Errorf("String() got %q want Airport|Express", got)
+	}
+	if got := DestinationFlags(0).String(); got != "" {
+		t.Errorf("String() of no flags got %q want empty", got)
+	}
+}
@@ -0,0 +1,208 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExhausted is returned when a Limiter refuses to let a request through.
+var ErrQuotaExhausted = errors.New("request quota exhausted")
+
+// QuotaError captures why a Limiter refused a request.
+type QuotaError struct {
+	Endpoint string    // Endpoint the request was for
+	Daily    bool      // True if the daily budget, rather than the rate limit, was hit
+	ResetAt  time.Time // Earliest time a request is expected to be allowed
+}
+
+func (e *QuotaError) Error() string {
+	kind := "rate limit"
+	if e.Daily {
+		kind = "daily budget"
+	}
+	return fmt.Sprintf("%s for %s exhausted until %s", kind, e.Endpoint, e.ResetAt.Format(time.RFC3339))
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExhausted
+}
+
+// A Limiter gates requests made by the client.
+//
+// Wait is called before every HTTP request, including retries and the
+// getToken and isValidToken calls made in AuthToken mode. It should
+// block until the request may proceed or return an error if it may not.
+type Limiter interface {
+	Wait(ctx context.Context, endpoint string) error
+}
+
+// A RateLimit describes a token bucket.
+type RateLimit struct {
+	PerSecond float64 // Tokens added per second; 0 means unlimited
+	Burst     int     // Maximum number of tokens, at least 1
+}
+
+// QuotaConfig configures a QuotaLimiter.
+type QuotaConfig struct {
+	Default     RateLimit            // Rate limit for endpoints without an override
+	Endpoints   map[string]RateLimit // Per-endpoint overrides, keyed by endpoint name like "getVehicleDataXML"
+	DailyBudget int                  // Requests allowed in any rolling 24 hour window; 0 means unlimited
+	FailFast    bool                 // Return a QuotaError instead of blocking
+}
+
+// QuotaLimiter is a Limiter with a token bucket per endpoint and a rolling
+// daily budget shared across all endpoints.
+type QuotaLimiter struct {
+	cfg QuotaConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	calls   []time.Time // Timestamps of calls in the current 24 hour window, oldest first
+}
+
+type bucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+const budgetWindow = 24 * time.Hour
+
+// NewQuotaLimiter constructs a QuotaLimiter from the supplied config.
+func NewQuotaLimiter(cfg QuotaConfig) *QuotaLimiter {
+	return &QuotaLimiter{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Remaining returns how many requests are left in the rolling daily budget,
+// or -1 if there is no daily budget.
+func (l *QuotaLimiter) Remaining() int {
+	if l.cfg.DailyBudget <= 0 {
+		return -1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expireCalls(l.now())
+	return l.cfg.DailyBudget - len(l.calls)
+}
+
+// Wait blocks until both the endpoint's rate limit and the daily budget
+// allow a request, then records it.
+func (l *QuotaLimiter) Wait(ctx context.Context, endpoint string) error {
+	if err := l.wait(ctx, endpoint, l.takeToken); err != nil {
+		return err
+	}
+	if err := l.wait(ctx, endpoint, l.takeBudget); err != nil {
+		// The request is not being made, so it should not count against
+		// the rate limit either.
+		l.refundToken(endpoint)
+		return err
+	}
+	return nil
+}
+
+// wait repeatedly calls take until it succeeds, sleeping for the duration it
+// suggests in between. take returns a zero duration on success.
+func (l *QuotaLimiter) wait(ctx context.Context, endpoint string, take func(string, time.Time) (time.Duration, *QuotaError)) error {
+	for {
+		d, qerr := take(endpoint, l.now())
+		if qerr == nil {
+			return nil
+		}
+		if l.cfg.FailFast {
+			return qerr
+		}
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(qerr.ResetAt) {
+			return qerr
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// takeToken removes a token from the endpoint's bucket if one is available.
+func (l *QuotaLimiter) takeToken(endpoint string, now time.Time) (time.Duration, *QuotaError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[endpoint]
+	if !ok {
+		limit, ok := l.cfg.Endpoints[endpoint]
+		if !ok {
+			limit = l.cfg.Default
+		}
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		l.buckets[endpoint] = b
+	}
+	if b.limit.PerSecond <= 0 {
+		return 0, nil
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.PerSecond
+	if capacity := float64(b.limit.Burst); b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, nil
+	}
+	d := time.Duration((1 - b.tokens) / b.limit.PerSecond * float64(time.Second))
+	return d, &QuotaError{Endpoint: endpoint, ResetAt: now.Add(d)}
+}
+
+// refundToken returns a token taken by takeToken to the endpoint's bucket.
+func (l *QuotaLimiter) refundToken(endpoint string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[endpoint]; ok && b.limit.PerSecond > 0 {
+		b.tokens = math.Min(b.tokens+1, float64(b.limit.Burst))
+	}
+}
+
+// takeBudget records a call against the daily budget if any is left.
+func (l *QuotaLimiter) takeBudget(endpoint string, now time.Time) (time.Duration, *QuotaError) {
+	if l.cfg.DailyBudget <= 0 {
+		return 0, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.expireCalls(now)
+	if len(l.calls) < l.cfg.DailyBudget {
+		l.calls = append(l.calls, now)
+		return 0, nil
+	}
+	reset := l.calls[0].Add(budgetWindow)
+	return reset.Sub(now), &QuotaError{Endpoint: endpoint, Daily: true, ResetAt: reset}
+}
+
+// expireCalls drops calls that have fallen out of the rolling window.
+// l.mu must be held.
+func (l *QuotaLimiter) expireCalls(now time.Time) {
+	cutoff := now.Add(-budgetWindow)
+	i := 0
+	for i < len(l.calls) && !l.calls[i].After(cutoff) {
+		i++
+	}
+	l.calls = l.calls[i:]
+}
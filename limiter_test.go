@@ -0,0 +1,161 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for QuotaLimiter tests.
+type fakeClock struct{ t time.Time }
+
+func (f *fakeClock) now() time.Time          { return f.t }
+func (f *fakeClock) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func TestQuotaLimiterDailyBudget(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC)}
+	l := NewQuotaLimiter(QuotaConfig{DailyBudget: 2, FailFast: true})
+	l.now = clock.now
+
+	ctx := context.Background()
+	if got := l.Remaining(); got != 2 {
+		t.Errorf("Remaining() got %d want 2", got)
+	}
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(ctx, stationListEndpoint); err != nil {
+			t.Fatalf("Wait() #%d unexpected error: %v", i, err)
+		}
+		clock.advance(time.Hour)
+	}
+	if got := l.Remaining(); got != 0 {
+		t.Errorf("Remaining() got %d want 0", got)
+	}
+
+	err := l.Wait(ctx, stationListEndpoint)
+	if !errors.Is(err, ErrQuotaExhausted) {
+		t.Fatalf("Wait() expected ErrQuotaExhausted, got: %v", err)
+	}
+	var qerr *QuotaError
+	if !errors.As(err, &qerr) {
+		t.Fatalf("expected *QuotaError, got: %T", err)
+	}
+	if want := time.Date(2024, 5, 4, 8, 0, 0, 0, time.UTC); !qerr.Daily || !qerr.ResetAt.Equal(want) {
+		t.Errorf("QuotaError got %+v want daily reset at %v", qerr, want)
+	}
+
+	// The first call rolls out of the window a day later.
+	clock.advance(22 * time.Hour)
+	if got := l.Remaining(); got != 1 {
+		t.Errorf("Remaining() got %d want 1", got)
+	}
+	if err := l.Wait(ctx, stationListEndpoint); err != nil {
+		t.Errorf("Wait() unexpected error after window rolled: %v", err)
+	}
+}
+
+func TestQuotaLimiterUnlimited(t *testing.T) {
+	l := NewQuotaLimiter(QuotaConfig{})
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background(), vehicleDataEndpoint); err != nil {
+			t.Fatalf("Wait() unexpected error: %v", err)
+		}
+	}
+	if got := l.Remaining(); got != -1 {
+		t.Errorf("Remaining() got %d want -1", got)
+	}
+}
+
+func TestQuotaLimiterTokenBucket(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC)}
+	l := NewQuotaLimiter(QuotaConfig{
+		Default:   RateLimit{PerSecond: 1, Burst: 2},
+		Endpoints: map[string]RateLimit{vehicleDataEndpoint: {PerSecond: 0.1, Burst: 1}},
+		FailFast:  true,
+	})
+	l.now = clock.now
+	ctx := context.Background()
+
+	for _, r := range []struct {
+		endpoint string
+		advance  time.Duration
+		wantErr  bool
+	}{
+		{stationDataEndpoint, 0, false},
+		{stationDataEndpoint, 0, false},
+		{stationDataEndpoint, 0, true},
+		{stationDataEndpoint, time.Second, false},
+		{vehicleDataEndpoint, 0, false},
+		{vehicleDataEndpoint, time.Second, true},
+		{vehicleDataEndpoint, 9 * time.Second, false},
+	} {
+		clock.advance(r.advance)
+		err := l.Wait(ctx, r.endpoint)
+		if (err != nil) != r.wantErr {
+			t.Errorf("Wait(%s) after %v got error %v wantErr %v", r.endpoint, r.advance, err, r.wantErr)
+		}
+	}
+}
+
+func TestQuotaLimiterBudgetKeepsToken(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC)}
+	l := NewQuotaLimiter(QuotaConfig{
+		Default:     RateLimit{PerSecond: 0.001, Burst: 2},
+		DailyBudget: 1,
+		FailFast:    true,
+	})
+	l.now = clock.now
+	ctx := context.Background()
+
+	if err := l.Wait(ctx, stationDataEndpoint); err != nil {
+		t.Fatalf("Wait() unexpected error: %v", err)
+	}
+	var qerr *QuotaError
+	if err := l.Wait(ctx, stationDataEndpoint); !errors.As(err, &qerr) || !qerr.Daily {
+		t.Fatalf("Wait() got %v want a daily QuotaError", err)
+	}
+
+	// Once the budget frees up, the token the refused call took is back.
+	clock.advance(budgetWindow)
+	if err := l.Wait(ctx, stationDataEndpoint); err != nil {
+		t.Errorf("Wait() after the budget reset got %v, want the refunded token", err)
+	}
+}
+
+func TestQuotaLimiterBlocksUntilDeadline(t *testing.T) {
+	l := NewQuotaLimiter(QuotaConfig{Default: RateLimit{PerSecond: 0.01, Burst: 1}})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx, stationDataEndpoint); err != nil {
+		t.Fatalf("Wait() unexpected error: %v", err)
+	}
+	if err := l.Wait(ctx, stationDataEndpoint); !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("Wait() expected ErrQuotaExhausted, got: %v", err)
+	}
+}
+
+func TestClientLimiter(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.ServeFile(w, r, "testdata/getStationList.xml")
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	l := NewQuotaLimiter(QuotaConfig{DailyBudget: 1, FailFast: true})
+	c.SetLimiter(l)
+
+	if _, err := c.StationList(context.Background()); err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+	if _, err := c.StationList(context.Background()); !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("StationList() expected ErrQuotaExhausted, got: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call to reach the server, got %d", calls)
+	}
+}
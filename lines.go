@@ -0,0 +1,115 @@
+package njtapi
+
+import "strings"
+
+// A Line is a train line, like the North Jersey Coast Line.
+//
+// Every endpoint resolves the names, codes and abbreviations it sends for a
+// known line to the same Line value, so lines can be compared with ==.
+// Lines which are not known, like Amtrak's, carry only the name the API
+// sent, or its code if there is no name, since endpoints differ in which
+// codes and colors they send. Colors sent for them are still available from
+// StationTrain.BackColor.
+type Line struct {
+	Code  string // Line code, like "NC"
+	Name  string // Display name, like "North Jersey Coast Line"
+	Color Color  // Line color on departure boards and maps, or zero if the line is not known
+}
+
+func (l Line) String() string {
+	return l.Name
+}
+
+// Aliases returns the other names and abbreviations the API uses for a
+// known line, like "NJCL". It returns nil for lines which are not known.
+func (l Line) Aliases() []string {
+	for _, e := range lineRegistry {
+		if e.line == l {
+			return append([]string(nil), e.aliases...)
+		}
+	}
+	return nil
+}
+
+// Lines operated by NJ Transit.
+var (
+	lineNortheastCorridor = Line{"NE", "Northeast Corridor Line", Color{0xF7, 0x50, 0x5E}}
+	lineNorthJerseyCoast  = Line{"NC", "North Jersey Coast Line", Color{0x00, 0x9C, 0xDB}}
+	lineRaritanValley     = Line{"RV", "Raritan Valley Line", Color{0xFF, 0x99, 0x3E}}
+	lineMorristown        = Line{"ME", "Morristown Line", Color{0x00, 0x95, 0x3B}}
+	lineMontclairBoonton  = Line{"MC", "Montclair-Boonton Line", Color{0xC3, 0x63, 0x66}}
+	lineBergenCounty      = Line{"BC", "Bergen County Line", Color{0x98, 0xA8, 0xBF}}
+	lineMain              = Line{"MA", "Main Line", Color{0xFF, 0xD0, 0x06}}
+	linePascackValley     = Line{"PV", "Pascack Valley Line", Color{0xA0, 0x21, 0x8C}}
+	linePortJervis        = Line{"PJ", "Port Jervis Line", Color{0xFF, 0x79, 0x00}}
+	lineAtlanticCity      = Line{"AC", "Atlantic City Rail Line", Color{0x00, 0x5D, 0xAA}}
+	lineGladstone         = Line{"GS", "Gladstone Branch", Color{0xA1, 0xD5, 0xAE}}
+	linePrinceton         = Line{"PR", "Princeton Branch", Color{0xF7, 0x50, 0x5E}}
+)
+
+// lineRegistry lists every known line with its aliases, in the order
+// returned by Lines.
+var lineRegistry = []struct {
+	line    Line
+	aliases []string
+}{
+	{lineNortheastCorridor, []string{"NEC", "Northeast Corridor"}},
+	{lineNorthJerseyCoast, []string{"NJCL", "North Jersey Coast"}},
+	{lineRaritanValley, []string{"RARV", "Raritan Valley"}},
+	{lineMorristown, []string{"ME Line", "M&E", "Morris & Essex", "Morris & Essex Line", "Morristown"}},
+	{lineMontclairBoonton, []string{"MB", "MOBO", "Montclair-Boonton", "Montclair Boonton Line"}},
+	{lineBergenCounty, []string{"BERG", "Bergen County", "Bergen Line"}},
+	{lineMain, []string{"MAIN"}},
+	{linePascackValley, []string{"PASC", "Pascack Valley"}},
+	{linePortJervis, []string{"PORT", "Port Jervis"}},
+	{lineAtlanticCity, []string{"ACRL", "Atlantic City Line", "Atlantic City"}},
+	{lineGladstone, []string{"GLAD", "Gladstone Line", "Gladstone"}},
+	{linePrinceton, []string{"PRIN", "Princeton Shuttle", "Princeton Dinky", "Dinky"}},
+}
+
+// linesByName maps normalized codes, names and aliases to lines.
+var linesByName = func() map[string]Line {
+	lines := map[string]Line{}
+	for _, e := range lineRegistry {
+		for _, n := range append([]string{e.line.Code, e.line.Name}, e.aliases...) {
+			lines[normalizeName(n)] = e.line
+		}
+	}
+	return lines
+}()
+
+// Lines returns every known line.
+func Lines() []Line {
+	lines := make([]Line, len(lineRegistry))
+	for i, e := range lineRegistry {
+		lines[i] = e.line
+	}
+	return lines
+}
+
+// LookupLine finds a known line by its code, name or one of its aliases,
+// ignoring case.
+func LookupLine(s string) (Line, bool) {
+	l, ok := linesByName[normalizeName(s)]
+	return l, ok
+}
+
+// resolveLine returns the known line matching code or name, or a Line
+// with just the name (or code, if there is no name) if neither is known. It
+// returns the zero Line if both are empty.
+func resolveLine(code, name string) Line {
+	code, name = strings.TrimSpace(code), strings.TrimSpace(name)
+	if code == "" && name == "" {
+		return Line{}
+	}
+	if l, ok := LookupLine(code); ok {
+		return l
+	}
+	if l, ok := LookupLine(name); ok {
+		return l
+	}
+	if name == "" {
+		return Line{Code: code}
+	}
+	return Line{Name: name}
+}
@@ -0,0 +1,122 @@
+package njtapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLookupLine(t *testing.T) {
+	for _, r := range []struct {
+		s    string
+		want Line
+	}{
+		{"NC", lineNorthJerseyCoast},
+		{"NJCL", lineNorthJerseyCoast},
+		{"  northeast   CORRIDOR line ", lineNortheastCorridor},
+		{"ME Line", lineMorristown},
+		{"Morris & Essex Line", lineMorristown},
+		{"Gladstone Branch", lineGladstone},
+		{"REGIONAL", Line{}},
+		{"", Line{}},
+	} {
+		got, ok := LookupLine(r.s)
+		if got != r.want || ok != (r.want != Line{}) {
+			t.Errorf("LookupLine(%q) got %v, %v want %v", r.s, got, ok, r.want)
+		}
+	}
+}
+
+func TestLineRegistryKeys(t *testing.T) {
+	seen := map[string]Line{}
+	for _, l := range Lines() {
+		if l.Color == (Color{}) {
+			t.Errorf("%s has no color", l.Name)
+		}
+		for _, n := range append([]string{l.Code, l.Name}, l.Aliases()...) {
+			if other, ok := seen[normalizeName(n)]; ok && other != l {
+				t.Errorf("%q names both %s and %s", n, other.Name, l.Name)
+			}
+			seen[normalizeName(n)] = l
+		}
+	}
+}
+
+func TestResolveLine(t *testing.T) {
+	if got := resolveLine("NJCL", "North Jersey Coast Line"); got != lineNorthJerseyCoast {
+		t.Errorf("resolveLine() got %+v want the registry line", got)
+	}
+	if got := resolveLine("", " Raritan Valley Line"); got != lineRaritanValley {
+		t.Errorf("resolveLine() got %+v want the registry line", got)
+	}
+	if got := resolveLine(" ", ""); got != (Line{}) {
+		t.Errorf("resolveLine() got %+v want the zero Line", got)
+	}
+	// Unknown lines match however an endpoint sends them.
+	if got, want := resolveLine("AMTK", "REGIONAL"), resolveLine("", "REGIONAL"); got != want || got != (Line{Name: "REGIONAL"}) {
+		t.Errorf("resolveLine() got %+v and %+v want both %+v", got, want, Line{Name: "REGIONAL"})
+	}
+	if got := resolveLine("XX", ""); got != (Line{Code: "XX"}) {
+		t.Errorf("resolveLine() got %+v want the code alone", got)
+	}
+	if got := resolveLine("AMTK", "REGIONAL"); got.Aliases() != nil {
+		t.Errorf("Aliases() of an unknown line got %v want none", got.Aliases())
+	}
+}
+
+func TestLinesAreCopies(t *testing.T) {
+	lines := Lines()
+	lines[0].Name = "Changed"
+	if l, _ := LookupLine("NE"); l.Name != "Northeast Corridor Line" {
+		t.Errorf("changing a result of Lines() changed the registry: %+v", l)
+	}
+	l, _ := LookupLine("NJCL")
+	aliases := l.Aliases()
+	aliases[0] = "Changed"
+	if l.Aliases()[0] != "NJCL" {
+		t.Errorf("changing the result of Aliases() changed the registry: %v", l.Aliases())
+	}
+}
+
+func TestLinesSharedAcrossEndpoints(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + stationDataEndpoint:
+			http.ServeFile(w, r, "testdata/getTrainSchedule1.xml")
+		case "/" + trainStopsEndpoint:
+			http.ServeFile(w, r, "testdata/getTrainStopList1.xml")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	ctx := context.Background()
+	station, err := c.StationData(ctx, "SE")
+	if err != nil {
+		t.Fatalf("StationData() unexpected error: %v", err)
+	}
+	train, err := c.GetTrainStops(ctx, NJTTrainID(1085))
+	if err != nil {
+		t.Fatalf("GetTrainStops() unexpected error: %v", err)
+	}
+
+	var fromStation, fromStops Line
+	for _, d := range station.Departures {
+		if d.Line.Code == "NC" {
+			fromStation = d.Line
+		}
+	}
+	for _, s := range train.Stops {
+		for _, l := range s.Lines {
+			if l.Code == "NC" {
+				fromStops = l
+			}
+		}
+	}
+	if fromStation != lineNorthJerseyCoast || fromStation != fromStops {
+		t.Errorf("North Jersey Coast Line from StationData (%+v) and GetTrainStops (%+v) are not the same Line", fromStation, fromStops)
+	}
+}
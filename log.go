@@ -0,0 +1,52 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// discardHandler is a slog.Handler which drops every record.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// discardLogger is used when no logger has been configured.
+var discardLogger = slog.New(discardHandler{})
+
+// SetLogger configures a logger for requests and for records skipped or only
+// partially parsed from responses. Requests and duplicate trains are logged
+// at debug level, and anything else dropped or unparseable at warn level.
+// Passing nil disables logging, which is the default.
+//
+// It should be called before the client is used.
+func (c *Client) SetLogger(l *slog.Logger) {
+	c.logger = l
+}
+
+// log returns the configured logger, or one which discards everything.
+func (c *Client) log() *slog.Logger {
+	if c.logger == nil {
+		return discardLogger
+	}
+	return c.logger
+}
+
+// logParseErrors emits a warning for each ParseError in errs.
+func (c *Client) logParseErrors(ctx context.Context, endpoint, trainID string, errs []error) {
+	for _, err := range errs {
+		attrs := []slog.Attr{
+			slog.String("endpoint", endpoint),
+			slog.String("train_id", trainID),
+		}
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			attrs = append(attrs, slog.String("field", pe.Field), slog.String("value", pe.Value))
+		}
+		attrs = append(attrs, slog.Any("error", err))
+		c.log().LogAttrs(ctx, slog.LevelWarn, "njtapi: unparseable field", attrs...)
+	}
+}
@@ -0,0 +1,75 @@
+package njtapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// logRecords decodes the JSON log lines in buf, keeping only the given keys.
+func logRecords(t *testing.T, buf *bytes.Buffer, keys ...string) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var all map[string]any
+		if err := json.Unmarshal([]byte(line), &all); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		r := map[string]any{}
+		for _, k := range keys {
+			if v, ok := all[k]; ok {
+				r[k] = v
+			}
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLogging(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<TRAINS>
+			<TRAIN><ID>1</ID><LAST_MODIFIED>01-Jan-2024 10:00:00 AM</LAST_MODIFIED><SCHED_DEP_TIME>01-Jan-2024 10:00:00 AM</SCHED_DEP_TIME></TRAIN>
+			<TRAIN><ID>1.</ID><LAST_MODIFIED>01-Jan-2024 10:05:00 AM</LAST_MODIFIED><SCHED_DEP_TIME>01-Jan-2024 10:00:00 AM</SCHED_DEP_TIME></TRAIN>
+			<TRAIN><ID>2</ID><LAST_MODIFIED>soon</LAST_MODIFIED><SCHED_DEP_TIME>01-Jan-2024 10:00:00 AM</SCHED_DEP_TIME></TRAIN>
+		</TRAINS>`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if _, err := c.VehicleData(context.Background()); err != nil {
+		t.Fatalf("VehicleData() unexpected error: %v", err)
+	}
+	want := []map[string]any{
+		{"level": "DEBUG", "msg": "njtapi: request", "endpoint": vehicleDataEndpoint, "status": 200.0},
+		{"level": "WARN", "msg": "njtapi: unparseable field", "endpoint": vehicleDataEndpoint, "train_id": "2", "field": "LAST_MODIFIED", "value": "soon"},
+		{"level": "DEBUG", "msg": "njtapi: removing duplicate train", "train_id": "1"},
+	}
+	keys := []string{"level", "msg", "endpoint", "status", "train_id", "field", "value"}
+	if diff := cmp.Diff(want, logRecords(t, &buf, keys...)); diff != "" {
+		t.Errorf("VehicleData() logs mismatch (-want +got):\n%s", diff)
+	}
+	if strings.Contains(buf.String(), "pa$$word") || strings.Contains(buf.String(), "pa%24%24word") {
+		t.Errorf("logs contain the password: %s", buf.String())
+	}
+}
+
+func TestNoLogger(t *testing.T) {
+	c := &Client{}
+	if c.log().Enabled(context.Background(), slog.LevelError) {
+		t.Error("log() without a logger should discard records")
+	}
+}
@@ -0,0 +1,69 @@
+package njtapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// A Request is a single HTTP call to an API endpoint, as seen by Middleware.
+type Request struct {
+	Endpoint string            // Endpoint being called, like "getTrainScheduleXML" or "getToken"
+	Params   map[string]string // Endpoint parameters, excluding credentials and tokens
+	HTTP     *http.Request     // Outgoing request; middleware may add headers
+}
+
+// RedactedURL returns the request URL with credentials removed, suitable
+// for logging.
+func (r *Request) RedactedURL() string {
+	return redactURL(r.HTTP.URL.String())
+}
+
+// A Response is the raw result of a Request.
+//
+// Non-2xx responses are passed through middleware as a Response rather than
+// an error; the client turns them into an *APIError afterwards.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// RoundTripFunc sends a Request and returns its Response.
+type RoundTripFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a RoundTripFunc to observe or alter requests and
+// responses, e.g. for logging, metrics, header injection or fault injection.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Use appends middleware to the client. The first middleware added is the
+// outermost, so it sees the request first and the response last.
+//
+// Middleware runs once per HTTP request, so it sees every retry and token
+// refresh individually. It should be called before the client is used.
+func (c *Client) Use(mw ...Middleware) {
+	c.middleware = append(c.middleware, mw...)
+}
+
+// roundTrip sends req through the middleware chain.
+func (c *Client) roundTrip(ctx context.Context, req *Request) (*Response, error) {
+	rt := c.send
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	return rt(ctx, req)
+}
+
+// send is the innermost RoundTripFunc, which makes the actual HTTP request.
+func (c *Client) send(ctx context.Context, req *Request) (*Response, error) {
+	resp, err := c.httpClient.Do(req.HTTP.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
+}
@@ -0,0 +1,132 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMiddlewareOrderAndRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Team"); got != "departures" {
+			t.Errorf("X-Team header got %q want departures", got)
+		}
+		http.ServeFile(w, r, "testdata/getTrainSchedule1.xml")
+	}))
+	defer ts.Close()
+
+	var events []string
+	var seen *Request
+	var captured []byte
+	tag := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(ctx context.Context, req *Request) (*Response, error) {
+				events = append(events, name+" request")
+				resp, err := next(ctx, req)
+				events = append(events, name+" response")
+				return resp, err
+			}
+		}
+	}
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.Use(tag("outer"), tag("inner"))
+	c.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			seen = req
+			req.HTTP.Header.Set("X-Team", "departures")
+			resp, err := next(ctx, req)
+			if resp != nil {
+				captured = resp.Body
+			}
+			return resp, err
+		}
+	})
+
+	if _, err := c.StationData(context.Background(), "SE"); err != nil {
+		t.Fatalf("StationData() unexpected error: %v", err)
+	}
+
+	want := []string{"outer request", "inner request", "inner response", "outer response"}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("middleware order mismatch (-want +got):\n%s", diff)
+	}
+	if seen.Endpoint != stationDataEndpoint {
+		t.Errorf("Request.Endpoint got %q want %q", seen.Endpoint, stationDataEndpoint)
+	}
+	if diff := cmp.Diff(map[string]string{"station": "SE"}, seen.Params); diff != "" {
+		t.Errorf("Request.Params mismatch (-want +got):\n%s", diff)
+	}
+	if u := seen.RedactedURL(); strings.Contains(u, "pa$$word") || strings.Contains(u, "pa%24%24word") || !strings.Contains(u, "station=SE") {
+		t.Errorf("RedactedURL() got %q", u)
+	}
+	if !strings.Contains(string(captured), "<STATION_2CHAR>SE</STATION_2CHAR>") {
+		t.Errorf("captured body missing payload: %q", captured)
+	}
+}
+
+func TestMiddlewareFaultInjection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/getStationList.xml")
+	}))
+	defer ts.Close()
+
+	failures := 2
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	c.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			if failures > 0 {
+				failures--
+				return &Response{StatusCode: http.StatusServiceUnavailable, Body: []byte("injected")}, nil
+			}
+			return next(ctx, req)
+		}
+	})
+
+	if _, err := c.StationList(context.Background()); err != nil {
+		t.Fatalf("StationList() unexpected error after injected faults: %v", err)
+	}
+
+	failures = 5
+	_, err := c.StationList(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Body != "injected" {
+		t.Errorf("StationList() got %v want injected *APIError", err)
+	}
+}
+
+func TestMiddlewareTokenMode(t *testing.T) {
+	api := &fakeTokenAPI{t: t}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	var calls []*Request
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetAuthMode(AuthToken)
+	c.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			calls = append(calls, req)
+			return next(ctx, req)
+		}
+	})
+
+	if _, err := c.fetch(context.Background(), trainStopsEndpoint, map[string]string{"trainID": "1085"}); err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected getToken and getTrainStopList calls, got %d", len(calls))
+	}
+	if calls[0].Endpoint != "getToken" || len(calls[0].Params) != 0 {
+		t.Errorf("first call got %s %v want getToken without params", calls[0].Endpoint, calls[0].Params)
+	}
+	if diff := cmp.Diff(map[string]string{"train": "1085"}, calls[1].Params); calls[1].Endpoint != "getTrainStopList" || diff != "" {
+		t.Errorf("second call got %s, params mismatch (-want +got):\n%s", calls[1].Endpoint, diff)
+	}
+}
@@ -0,0 +1,313 @@
+package njtapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNoInteraction is returned by a Replayer for a request which is not in
+// its cassette.
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// redacted replaces secrets in recorded cassettes.
+const redacted = "REDACTED"
+
+// secretParams are request parameters which are never recorded or matched.
+var secretParams = map[string]bool{"username": true, "password": true, "token": true}
+
+// An Interaction is a recorded request and its response.
+type Interaction struct {
+	Endpoint    string            `json:"endpoint"`
+	Params      map[string]string `json:"params,omitempty"` // Request parameters, excluding credentials and tokens
+	StatusCode  int               `json:"status_code"`
+	ContentType string            `json:"content_type,omitempty"`
+	Body        string            `json:"body"`
+}
+
+// A Cassette is a sequence of recorded interactions, stored as JSON.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette from a file.
+func LoadCassette(file string) (*Cassette, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", file, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to a file, replacing it atomically.
+func (c *Cassette) Save(file string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".cassette-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// readBody returns the body of a POST request, along with a request to
+// send in place of req. The caller's request is never modified: the body is
+// read from req.GetBody if possible, or else from a clone of req.
+func readBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.Method != http.MethodPost {
+		return req, nil, nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer func() { _ = rc.Close() }()
+		body, err := io.ReadAll(rc)
+		return req, body, err
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return out, body, nil
+}
+
+// requestKey returns the endpoint and non-secret parameters of an API
+// request, whether they are passed in the query string or in form, the
+// request's body. The secret values found are returned separately.
+func requestKey(req *http.Request, form []byte) (endpoint string, params map[string]string, secrets []string, err error) {
+	values := req.URL.Query()
+	if len(form) > 0 {
+		f, err := url.ParseQuery(string(form))
+		if err != nil {
+			return "", nil, nil, err
+		}
+		for k, v := range f {
+			values[k] = v
+		}
+	}
+
+	params = map[string]string{}
+	for k := range values {
+		if secretParams[k] {
+			if v := values.Get(k); v != "" {
+				secrets = append(secrets, v)
+			}
+			continue
+		}
+		params[k] = values.Get(k)
+	}
+	return path.Base(req.URL.Path), params, secrets, nil
+}
+
+// matchKey identifies interactions which answer the same request.
+func matchKey(endpoint string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(endpoint)
+	for _, k := range keys {
+		b.WriteString("&" + url.QueryEscape(k) + "=" + url.QueryEscape(params[k]))
+	}
+	return b.String()
+}
+
+// A Recorder is an http.RoundTripper which passes requests to the real API
+// and records each interaction. Credentials and tokens are scrubbed from
+// the recording.
+//
+//	rec := njtapitest.NewRecorder(nil)
+//	client := njtapi.NewCustomClient(&http.Client{Transport: rec}, baseURL, username, password)
+//	// ... make calls ...
+//	err := rec.Save("testdata/session.json")
+type Recorder struct {
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	secrets  map[string]bool
+}
+
+// NewRecorder returns a Recorder which sends requests with next, or
+// http.DefaultTransport if next is nil.
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{next: next, secrets: map[string]bool{}}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, form, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, params, secrets, err := requestKey(req, form)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range secrets {
+		r.secrets[s] = true
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Endpoint:    endpoint,
+		Params:      params,
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	})
+	return resp, nil
+}
+
+// Cassette returns the interactions recorded so far, with every credential
+// and token seen in any request scrubbed from every response body. This
+// covers tokens issued by one response and used by later requests.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var secrets []string
+	for s := range r.secrets {
+		secrets = append(secrets, s, url.QueryEscape(s))
+	}
+	// Replace longer secrets first so one containing another is fully removed.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+
+	c := &Cassette{Interactions: make([]Interaction, len(r.cassette.Interactions))}
+	for i, in := range r.cassette.Interactions {
+		for _, s := range secrets {
+			in.Body = strings.ReplaceAll(in.Body, s, redacted)
+		}
+		c.Interactions[i] = in
+	}
+	return c
+}
+
+// Save writes the scrubbed recording to a file.
+func (r *Recorder) Save(file string) error {
+	return r.Cassette().Save(file)
+}
+
+// A Replayer is an http.RoundTripper which answers requests from a
+// cassette without touching the network.
+//
+// Requests are matched by endpoint and non-secret parameters, so replaying
+// works with any credentials. Identical requests are answered with the
+// matching interactions in recorded order, repeating the last one once they
+// run out.
+type Replayer struct {
+	mu    sync.Mutex
+	queue map[string][]Interaction
+}
+
+// NewReplayer returns a Replayer for a cassette.
+func NewReplayer(c *Cassette) *Replayer {
+	r := &Replayer{queue: map[string][]Interaction{}}
+	for _, in := range c.Interactions {
+		k := matchKey(in.Endpoint, in.Params)
+		r.queue[k] = append(r.queue[k], in)
+	}
+	return r
+}
+
+// NewFileReplayer returns a Replayer for the cassette stored in file.
+func NewFileReplayer(file string) (*Replayer, error) {
+	c, err := LoadCassette(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(c), nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer func() { _ = req.Body.Close() }()
+	}
+	_, form, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, params, _, err := requestKey(req, form)
+	if err != nil {
+		return nil, err
+	}
+
+	k := matchKey(endpoint, params)
+	r.mu.Lock()
+	q := r.queue[k]
+	if len(q) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w for %s", ErrNoInteraction, k)
+	}
+	in := q[0]
+	if len(q) > 1 {
+		r.queue[k] = q[1:]
+	}
+	r.mu.Unlock()
+
+	header := http.Header{}
+	if in.ContentType != "" {
+		header.Set("Content-Type", in.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+		StatusCode:    in.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(in.Body)),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package njtapitest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bamnet/njtapi"
+	"github.com/bamnet/njtapi/njtapitest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecordReplay(t *testing.T) {
+	srv, _ := newFake(t)
+	file := filepath.Join(t.TempDir(), "session.json")
+	ctx := context.Background()
+
+	rec := njtapitest.NewRecorder(nil)
+	live := njtapi.NewCustomClient(&http.Client{Transport: rec}, srv.URL, njtapitest.Username, njtapitest.Password)
+	wantStation, err := live.StationData(ctx, "SE")
+	if err != nil {
+		t.Fatalf("StationData() unexpected error: %v", err)
+	}
+	wantTrains, err := live.VehicleData(ctx)
+	if err != nil {
+		t.Fatalf("VehicleData() unexpected error: %v", err)
+	}
+	if _, err := live.GetTrainMap(ctx, njtapi.NJTTrainID(1)); !errors.Is(err, njtapi.ErrTrainNotFound) {
+		t.Fatalf("GetTrainMap() got %v want ErrTrainNotFound", err)
+	}
+	if err := rec.Save(file); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	srv.Close()
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	if strings.Contains(string(b), njtapitest.Password) || strings.Contains(string(b), njtapitest.Username) {
+		t.Errorf("cassette contains credentials:\n%s", b)
+	}
+
+	rep, err := njtapitest.NewFileReplayer(file)
+	if err != nil {
+		t.Fatalf("NewFileReplayer() error: %v", err)
+	}
+	replay := njtapi.NewCustomClient(&http.Client{Transport: rep}, "http://replay.invalid/", "someone", "else")
+	gotStation, err := replay.StationData(ctx, "SE")
+	if err != nil {
+		t.Fatalf("replayed StationData() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(wantStation, gotStation); diff != "" {
+		t.Errorf("replayed StationData() mismatch (-want +got):\n%s", diff)
+	}
+	gotTrains, err := replay.VehicleData(ctx)
+	if err != nil {
+		t.Fatalf("replayed VehicleData() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(wantTrains, gotTrains); diff != "" {
+		t.Errorf("replayed VehicleData() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := replay.GetTrainMap(ctx, njtapi.NJTTrainID(1)); !errors.Is(err, njtapi.ErrTrainNotFound) {
+		t.Errorf("replayed GetTrainMap() got %v want ErrTrainNotFound", err)
+	}
+	if _, err := replay.StationData(ctx, "NY"); !errors.Is(err, njtapitest.ErrNoInteraction) {
+		t.Errorf("StationData() for unrecorded station got %v want ErrNoInteraction", err)
+	}
+}
+
+func TestRecorderScrubsTokens(t *testing.T) {
+	const token = "secret-token-1234"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/getToken" {
+			_, _ = w.Write([]byte(`{"Authenticated":"True","UserToken":"` + token + `"}`))
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("token") != token {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"STATION_2CHAR":"NY","STATIONNAME":"New York"}]`))
+	}))
+	defer ts.Close()
+
+	rec := njtapitest.NewRecorder(nil)
+	c := njtapi.NewCustomClient(&http.Client{Transport: rec}, ts.URL, "user", "pass")
+	c.SetAuthMode(njtapi.AuthToken)
+	want, err := c.StationList(context.Background())
+	if err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+
+	cassette := rec.Cassette()
+	for _, in := range cassette.Interactions {
+		if strings.Contains(in.Body, token) || in.Params["token"] != "" || in.Params["password"] != "" {
+			t.Errorf("interaction for %s leaks secrets: %+v", in.Endpoint, in)
+		}
+	}
+
+	replay := njtapi.NewCustomClient(&http.Client{Transport: njtapitest.NewReplayer(cassette)}, "http://replay.invalid/", "user", "pass")
+	replay.SetAuthMode(njtapi.AuthToken)
+	got, err := replay.StationList(context.Background())
+	if err != nil {
+		t.Fatalf("replayed StationList() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("replayed StationList() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecorderLeavesRequestAlone(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("station") != "NY" {
+			http.Error(w, "bad form", http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	rec := njtapitest.NewRecorder(nil)
+	for _, getBody := range []bool{true, false} {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/getTrainSchedule", strings.NewReader("station=NY"))
+		if err != nil {
+			t.Fatalf("NewRequest() error: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if !getBody {
+			req.GetBody = nil
+		}
+		body := req.Body
+
+		resp, err := rec.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip(GetBody: %t) unexpected error: %v", getBody, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("RoundTrip(GetBody: %t) got status %d want 200", getBody, resp.StatusCode)
+		}
+		if req.Body != body {
+			t.Errorf("RoundTrip(GetBody: %t) replaced the request body", getBody)
+		}
+	}
+	for _, in := range rec.Cassette().Interactions {
+		if in.Params["station"] != "NY" {
+			t.Errorf("interaction params got %v want station=NY", in.Params)
+		}
+	}
+}
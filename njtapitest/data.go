@@ -0,0 +1,144 @@
+package njtapitest
+
+import "time"
+
+// A Station is a station served by the fake API.
+type Station struct {
+	Code    string   // Two character station code, like "NY"
+	Name    string   // Station name, like "New York"
+	Banners []Banner // Advisories shown on the station's departure board
+}
+
+// A Banner is a station-wide advisory.
+type Banner struct {
+	ID    string    // Message ID
+	Type  string    // Message type, like "banner" or "alert"
+	Text  string    // Message text
+	Start time.Time // Time the message takes effect, omitted if zero
+	End   time.Time // Time the message expires, omitted if zero
+}
+
+// NewStation returns a Station with the given code and name.
+func NewStation(code, name string) Station {
+	return Station{Code: code, Name: name}
+}
+
+// A Train is a train served by the fake API.
+//
+// The same Train feeds every endpoint: it appears in the vehicle data, the
+// train map and stop list for its ID, and on the departure board of every
+// station it has not yet departed.
+type Train struct {
+	ID           string        // Train ID, like "3883" or "A137"
+	Line         string        // Line name, like "Northeast Corridor Line"
+	LineCode     string        // Line abbreviation, like "NEC"
+	Color        string        // Line color, like "#F7505E"
+	Direction    string        // Eastbound or Westbound
+	Destination  string        // Defaults to the name of the last stop
+	Status       string        // Departure board status, like "in 4 Min"
+	Delay        time.Duration // Delay reported as SEC_LATE
+	LastModified time.Time     // Time the train's position was last updated
+	Position     *Position     // Last known position, if any
+	TrackCircuit string        // Track circuit, like "AA-141UN"
+	NextStop     string        // Defaults to the name of the first stop not departed
+	Stops        []Stop        // Stops in order of travel
+}
+
+// A Position is a latitude and longitude.
+type Position struct {
+	Lat float64
+	Lng float64
+}
+
+// A Stop is a stop made by a Train.
+type Stop struct {
+	Code          string     // Station code, which links the stop to a departure board
+	Name          string     // Station name as shown for the stop
+	Time          time.Time  // Actual or planned departure time
+	DepartureTime time.Time  // Scheduled departure time, defaults to Time
+	Departed      bool       // Whether the train has left the stop
+	Status        string     // Stop status, like "OnTime" or "Cancelled"
+	Track         string     // Track shown on the departure board
+	Lines         []StopLine // Connecting lines at the stop
+}
+
+// A StopLine is a line connecting at a Stop.
+type StopLine struct {
+	Code  string // Line code, like "ME"
+	Name  string // Line name, like "ME Line"
+	Color string // Line color, like "#00953B"
+}
+
+// NewTrain returns a westbound Train on the given line, last updated now.
+func NewTrain(id, line, lineCode string) *Train {
+	return &Train{
+		ID:           id,
+		Line:         line,
+		LineCode:     lineCode,
+		Direction:    "Westbound",
+		Status:       "On Time",
+		LastModified: time.Now(),
+	}
+}
+
+// At sets the train's position and returns it, for chaining.
+func (t *Train) At(lat, lng float64) *Train {
+	t.Position = &Position{Lat: lat, Lng: lng}
+	return t
+}
+
+// Stop appends an on-time stop at the station and returns the train, for
+// chaining. Stops must be added in order of travel.
+func (t *Train) Stop(s Station, at time.Time) *Train {
+	t.Stops = append(t.Stops, Stop{Code: s.Code, Name: s.Name, Time: at, Status: "OnTime"})
+	return t
+}
+
+// DepartedThrough marks every stop up to and including the one at the
+// station code as departed and returns the train, for chaining.
+func (t *Train) DepartedThrough(code string) *Train {
+	for i := range t.Stops {
+		t.Stops[i].Departed = true
+		if t.Stops[i].Code == code {
+			break
+		}
+	}
+	return t
+}
+
+// destination returns the train's destination.
+func (t *Train) destination() string {
+	if t.Destination != "" || len(t.Stops) == 0 {
+		return t.Destination
+	}
+	return t.Stops[len(t.Stops)-1].Name
+}
+
+// nextStop returns the name of the train's next stop.
+func (t *Train) nextStop() string {
+	if t.NextStop != "" {
+		return t.NextStop
+	}
+	for _, s := range t.Stops {
+		if !s.Departed {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// scheduledDeparture returns the time the train is scheduled to leave its
+// first stop.
+func (t *Train) scheduledDeparture() time.Time {
+	if len(t.Stops) == 0 {
+		return time.Time{}
+	}
+	return t.Stops[0].departureTime()
+}
+
+func (s Stop) departureTime() time.Time {
+	if s.DepartureTime.IsZero() {
+		return s.Time
+	}
+	return s.DepartureTime
+}
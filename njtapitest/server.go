@@ -0,0 +1,297 @@
+// Package njtapitest provides a scriptable, in-memory fake of the NJTransit
+// API for testing code built on the njtapi package.
+//
+// The fake serves the five XML endpoints njtapi calls, checks credentials,
+// and can be told to add latency, fail, or return malformed XML:
+//
+//	srv := njtapitest.NewServer()
+//	defer srv.Close()
+//
+//	ny := njtapitest.NewStation("NY", "New York")
+//	se := njtapitest.NewStation("SE", "Secaucus")
+//	srv.AddStations(ny, se)
+//	srv.AddTrains(njtapitest.NewTrain("3883", "Northeast Corridor Line", "NEC").
+//		Stop(ny, departs).Stop(se, departs.Add(8*time.Minute)))
+//
+//	client := njtapi.NewClient(srv.URL, njtapitest.Username, njtapitest.Password)
+//
+// This package does not import njtapi, so njtapi's own tests can use it.
+package njtapitest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"time"
+)
+
+// Endpoints served by the fake.
+const (
+	StationDataEndpoint = "getTrainScheduleXML"
+	StationListEndpoint = "getStationListXML"
+	TrainMapEndpoint    = "getTrainMapXML"
+	TrainStopsEndpoint  = "getTrainStopListXML"
+	VehicleDataEndpoint = "getVehicleDataXML"
+)
+
+// Default credentials accepted by a new Server.
+const (
+	Username = "username"
+	Password = "password"
+)
+
+// A Fault alters the server's response to matching requests.
+type Fault struct {
+	Latency    time.Duration // Delay before responding
+	StatusCode int           // Respond with this status and Body instead of data, if non-zero
+	Body       string        // Body sent with StatusCode
+	Malformed  bool          // Truncate the XML payload so it cannot be parsed
+	Times      int           // Number of requests affected, or 0 for all of them
+}
+
+// A Server is a fake NJTransit API. Its methods are safe to call while it is
+// serving requests.
+type Server struct {
+	URL string // Base URL of the fake, suitable for njtapi.NewClient
+
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	username string
+	password string
+	loc      *time.Location
+	latency  time.Duration
+	stations []Station
+	trains   []*Train
+	faults   map[string][]*Fault
+	calls    map[string]int
+}
+
+// NewServer starts a fake API with no stations or trains. Close it when done.
+func NewServer() *Server {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		loc = time.UTC
+	}
+	s := &Server{
+		username: Username,
+		password: Password,
+		loc:      loc,
+		faults:   map[string][]*Fault{},
+		calls:    map[string]int{},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns an http.Client configured to talk to the server.
+func (s *Server) Client() *http.Client {
+	return s.srv.Client()
+}
+
+// SetCredentials changes the username and password the server accepts.
+func (s *Server) SetCredentials(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username, s.password = username, password
+}
+
+// SetLocation changes the timezone timestamps are rendered in. It defaults
+// to America/New_York, like the real API.
+func (s *Server) SetLocation(loc *time.Location) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loc = loc
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// AddStations adds stations to the station list, replacing any with the
+// same code.
+func (s *Server) AddStations(stations ...Station) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range stations {
+		replaced := false
+		for i := range s.stations {
+			if s.stations[i].Code == st.Code {
+				s.stations[i], replaced = st, true
+			}
+		}
+		if !replaced {
+			s.stations = append(s.stations, st)
+		}
+	}
+}
+
+// AddTrains adds trains to the server, replacing any with the same ID.
+// A train must not be modified once added; to update it, add a new Train
+// with the same ID.
+func (s *Server) AddTrains(trains ...*Train) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range trains {
+		replaced := false
+		for i := range s.trains {
+			if s.trains[i].ID == t.ID {
+				s.trains[i], replaced = t, true
+			}
+		}
+		if !replaced {
+			s.trains = append(s.trains, t)
+		}
+	}
+}
+
+// RemoveTrain removes the train with the given ID.
+func (s *Server) RemoveTrain(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.trains {
+		if t.ID == id {
+			s.trains = append(s.trains[:i], s.trains[i+1:]...)
+			return
+		}
+	}
+}
+
+// InjectFault applies f to requests for endpoint, or to every endpoint if
+// endpoint is empty. Faults are applied in the order they were injected,
+// and each request is affected by at most one.
+func (s *Server) InjectFault(endpoint string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = append(s.faults[endpoint], &f)
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = map[string][]*Fault{}
+}
+
+// Calls returns the number of requests received for endpoint, including
+// rejected ones.
+func (s *Server) Calls(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[endpoint]
+}
+
+// fault returns the fault to apply to a request for endpoint, if any,
+// consuming one of its uses.
+func (s *Server) fault(endpoint string) *Fault {
+	for _, key := range []string{endpoint, ""} {
+		for i, f := range s.faults[key] {
+			if f.Times > 0 {
+				f.Times--
+				if f.Times == 0 {
+					s.faults[key] = append(s.faults[key][:i], s.faults[key][i+1:]...)
+				}
+			}
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := path.Base(r.URL.Path)
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	s.calls[endpoint]++
+	latency := s.latency
+	var f Fault
+	if p := s.fault(endpoint); p != nil {
+		f = *p
+	}
+	authorized := q.Get("username") == s.username && q.Get("password") == s.password
+	payload, found := s.payload(endpoint, q.Get("station"), q.Get("trainID"))
+	s.mu.Unlock()
+
+	if d := latency + f.Latency; d > 0 {
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case !found:
+		http.NotFound(w, r)
+		return
+	case !authorized:
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	case f.StatusCode != 0:
+		w.WriteHeader(f.StatusCode)
+		_, _ = w.Write([]byte(f.Body))
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(payload); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body := buf.Bytes()
+	if f.Malformed {
+		body = body[:len(body)/2]
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	_, _ = w.Write(body)
+}
+
+// payload builds the response for an endpoint. It must be called with mu
+// held.
+func (s *Server) payload(endpoint, station, trainID string) (any, bool) {
+	r := renderer{loc: s.loc}
+	switch endpoint {
+	case StationListEndpoint:
+		return r.stationList(s.stations), true
+	case StationDataEndpoint:
+		st := Station{Code: station}
+		for _, candidate := range s.stations {
+			if candidate.Code == station {
+				st = candidate
+			}
+		}
+		return r.stationData(st, s.trains), true
+	case TrainMapEndpoint:
+		return r.trainMap(trainID, s.train(trainID)), true
+	case TrainStopsEndpoint:
+		return r.trainStops(s.train(trainID)), true
+	case VehicleDataEndpoint:
+		return r.vehicleData(s.trains), true
+	}
+	return nil, false
+}
+
+func (s *Server) train(id string) *Train {
+	for _, t := range s.trains {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package njtapitest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bamnet/njtapi"
+	"github.com/bamnet/njtapi/njtapitest"
+)
+
+func newFake(t *testing.T) (*njtapitest.Server, time.Time) {
+	t.Helper()
+	srv := njtapitest.NewServer()
+	t.Cleanup(srv.Close)
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Error loading timezones: %v", err)
+	}
+	departs := time.Date(2024, 5, 3, 20, 7, 0, 0, loc)
+
+	ny := njtapitest.NewStation("NY", "New York Penn Station")
+	se := njtapitest.NewStation("SE", "Secaucus Upper Lvl")
+	se.Banners = []njtapitest.Banner{{ID: "1", Type: "alert", Text: "Expect delays", Start: departs}}
+	np := njtapitest.NewStation("NP", "Newark Penn Station")
+	srv.AddStations(ny, se, np)
+
+	nec := njtapitest.NewTrain("3883", "Northeast Corridor Line", "NEC").
+		At(40.7706, -74.0403).
+		Stop(ny, departs).Stop(se, departs.Add(10*time.Minute)).Stop(np, departs.Add(20*time.Minute)).
+		DepartedThrough("NY")
+	nec.Status = "in 4 Min"
+	nec.Delay = 4 * time.Minute
+	nec.LastModified = departs.Add(5 * time.Minute)
+	amtrak := njtapitest.NewTrain("A137", "Amtrak", "AMTK").
+		Stop(ny, departs.Add(30*time.Minute)).Stop(np, departs.Add(45*time.Minute))
+	srv.AddTrains(nec, amtrak)
+	return srv, departs
+}
+
+func TestServer(t *testing.T) {
+	srv, departs := newFake(t)
+	c := njtapi.NewClient(srv.URL, njtapitest.Username, njtapitest.Password)
+	ctx := context.Background()
+
+	stations, err := c.StationList(ctx)
+	if err != nil || len(stations) != 3 || stations[1].ID != "SE" {
+		t.Errorf("StationList() got %v, %v", stations, err)
+	}
+
+	station, err := c.StationData(ctx, "SE")
+	if err != nil {
+		t.Fatalf("StationData() unexpected error: %v", err)
+	}
+	if len(station.Banners) != 1 || station.Banners[0].Severity != njtapi.SeverityAlert || !station.Banners[0].Start.Equal(departs) {
+		t.Errorf("StationData() banners got %+v", station.Banners)
+	}
+	if station.Name != "Secaucus Upper Lvl" || len(station.Departures) != 1 {
+		t.Fatalf("StationData() got %+v", station)
+	}
+	d := station.Departures[0]
+	if d.Destination != "Newark Penn Station" || d.Status.Kind != njtapi.StatusOnTime || d.Status.Minutes != 4 || d.SecondsLate != 4*time.Minute ||
+		!d.ScheduledDepartureDate.Equal(departs.Add(10*time.Minute)) || len(d.Stops) != 3 || !d.Stops[0].Departed {
+		t.Errorf("StationData() departure got %+v", d)
+	}
+
+	train, err := c.GetTrainMap(ctx, njtapi.NJTTrainID(3883))
+	if err != nil || train.Line.Code != "NE" || train.LatLng == nil || train.LatLng.Lat != 40.7706 {
+		t.Errorf("GetTrainMap() got %+v, %v", train, err)
+	}
+	if _, err := c.GetTrainMap(ctx, njtapi.NJTTrainID(1)); !errors.Is(err, njtapi.ErrTrainNotFound) {
+		t.Errorf("GetTrainMap(unknown) got %v want ErrTrainNotFound", err)
+	}
+
+	train, err = c.GetTrainStops(ctx, njtapi.NJTTrainID(3883))
+	if err != nil || len(train.Stops) != 3 || train.Stops[1].Name != "Secaucus Upper Lvl" {
+		t.Errorf("GetTrainStops() got %+v, %v", train, err)
+	}
+	if _, err := c.GetTrainStops(ctx, njtapi.NJTTrainID(1)); !errors.Is(err, njtapi.ErrTrainNotFound) {
+		t.Errorf("GetTrainStops(unknown) got %v want ErrTrainNotFound", err)
+	}
+
+	trains, err := c.VehicleData(ctx)
+	if err != nil || len(trains) != 2 || trains[0].NextStop != "Secaucus Upper Lvl" || trains[0].SecondsLate != 4*time.Minute {
+		t.Errorf("VehicleData() got %+v, %v", trains, err)
+	}
+
+	srv.RemoveTrain("3883")
+	if station, err := c.StationData(ctx, "SE"); err != nil || len(station.Departures) != 0 {
+		t.Errorf("StationData() after RemoveTrain got %+v, %v", station, err)
+	}
+	if got := srv.Calls(njtapitest.StationDataEndpoint); got != 2 {
+		t.Errorf("Calls(StationDataEndpoint) got %d want 2", got)
+	}
+}
+
+func TestServerCredentials(t *testing.T) {
+	srv, _ := newFake(t)
+	c := njtapi.NewClient(srv.URL, njtapitest.Username, "wrong")
+	var apiErr *njtapi.APIError
+	if _, err := c.VehicleData(context.Background()); !errors.As(err, &apiErr) || apiErr.StatusCode != 401 {
+		t.Errorf("VehicleData() with bad password got %v want 401", err)
+	}
+
+	srv.SetCredentials(njtapitest.Username, "wrong")
+	if _, err := c.VehicleData(context.Background()); err != nil {
+		t.Errorf("VehicleData() after SetCredentials got %v", err)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	srv, _ := newFake(t)
+	c := njtapi.NewClient(srv.URL, njtapitest.Username, njtapitest.Password)
+	ctx := context.Background()
+
+	srv.InjectFault(njtapitest.VehicleDataEndpoint, njtapitest.Fault{StatusCode: 503, Body: "maintenance", Times: 1})
+	var apiErr *njtapi.APIError
+	if _, err := c.VehicleData(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != 503 || apiErr.Body != "maintenance" {
+		t.Errorf("VehicleData() got %v want injected 503", err)
+	}
+	if _, err := c.VehicleData(ctx); err != nil {
+		t.Errorf("VehicleData() after fault expired got %v", err)
+	}
+
+	srv.InjectFault("", njtapitest.Fault{Malformed: true, Times: 1})
+	if _, err := c.StationList(ctx); err == nil {
+		t.Error("StationList() with malformed XML expected error, got none")
+	}
+
+	srv.SetLatency(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.StationList(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StationList() with latency got %v want DeadlineExceeded", err)
+	}
+}
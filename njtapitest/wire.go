@@ -0,0 +1,288 @@
+package njtapitest
+
+import (
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// timeLayout is the timestamp format used by the API.
+const timeLayout = "02-Jan-2006 03:04:05 PM"
+
+type stationListXML struct {
+	XMLName  xml.Name         `xml:"STATIONS"`
+	Stations []stationItemXML `xml:"STATION"`
+}
+
+type stationItemXML struct {
+	Code string `xml:"STATION_2CHAR"`
+	Name string `xml:"STATIONNAME"`
+}
+
+type stationDataXML struct {
+	XMLName xml.Name             `xml:"STATION"`
+	Code    string               `xml:"STATION_2CHAR"`
+	Name    string               `xml:"STATIONNAME"`
+	Banners []bannerXML          `xml:"BANNERMSGS>BANNERMSG"`
+	Items   []stationDataItemXML `xml:"ITEMS>ITEM"`
+}
+
+type bannerXML struct {
+	ID    string `xml:"MSG_ID"`
+	Type  string `xml:"MSG_TYPE"`
+	Text  string `xml:"MSG_TEXT"`
+	Start string `xml:"MSG_PUBDATE"`
+	End   string `xml:"MSG_EXPDATE"`
+}
+
+type stationDataItemXML struct {
+	Index              int                  `xml:"ITEM_INDEX"`
+	ScheduledDeparture string               `xml:"SCHED_DEP_DATE"`
+	Destination        string               `xml:"DESTINATION"`
+	Track              string               `xml:"TRACK"`
+	Line               string               `xml:"LINE"`
+	TrainID            string               `xml:"TRAIN_ID"`
+	ConnectingTrainID  string               `xml:"CONNECTING_TRAIN_ID"`
+	Status             string               `xml:"STATUS"`
+	SecondsLate        int                  `xml:"SEC_LATE"`
+	LastModified       string               `xml:"LAST_MODIFIED"`
+	BackColor          string               `xml:"BACKCOLOR"`
+	ForeColor          string               `xml:"FORECOLOR"`
+	ShadowColor        string               `xml:"SHADOWCOLOR"`
+	Latitude           string               `xml:"GPSLATITUDE"`
+	Longitude          string               `xml:"GPSLONGITUDE"`
+	GPSTime            string               `xml:"GPSTIME"`
+	StationPosition    int                  `xml:"STATION_POSITION"`
+	LineAbbreviation   string               `xml:"LINEABBREVIATION"`
+	InlineMsg          string               `xml:"INLINEMSG"`
+	Stops              []stationDataStopXML `xml:"STOPS>STOP"`
+}
+
+type stationDataStopXML struct {
+	Name     string `xml:"NAME"`
+	Time     string `xml:"TIME"`
+	Departed string `xml:"DEPARTED"`
+	Status   string `xml:"STOP_STATUS"`
+}
+
+type trainMapXML struct {
+	XMLName xml.Name        `xml:"Trains"`
+	Trains  []trainMapTrain `xml:"Train"`
+}
+
+type trainMapTrain struct {
+	ID           string `xml:"Train_ID"`
+	Direction    string `xml:"DIRECTION,omitempty"`
+	Line         string `xml:"TrainLine,omitempty"`
+	LastModified string `xml:"LAST_MODIFIED,omitempty"`
+	BackColor    string `xml:"BACKCOLOR,omitempty"`
+	TrackCircuit string `xml:"TrackCKT,omitempty"`
+	Latitude     string `xml:"latitude,omitempty"`
+	Longitude    string `xml:"longitude,omitempty"`
+}
+
+type trainStopsXML struct {
+	XMLName     xml.Name       `xml:"Train"`
+	ID          string         `xml:"Train_ID"`
+	Destination string         `xml:"DESTINATION,omitempty"`
+	GPSTime     string         `xml:"GPSTIME,omitempty"`
+	Longitude   string         `xml:"GPSLONGITUDE,omitempty"`
+	Latitude    string         `xml:"GPSLATITUDE,omitempty"`
+	Stops       []trainStopXML `xml:"STOPS>STOP"`
+}
+
+type trainStopXML struct {
+	Name          string        `xml:"NAME"`
+	Code          string        `xml:"STATION_2CHAR"`
+	Time          string        `xml:"TIME"`
+	Departed      string        `xml:"DEPARTED"`
+	Status        string        `xml:"STOP_STATUS"`
+	DepartureTime string        `xml:"DEP_TIME"`
+	Lines         []stopLineXML `xml:"STOP_LINES>STOP_LINE"`
+}
+
+type stopLineXML struct {
+	Code  string `xml:"LINE_CODE"`
+	Name  string `xml:"LINE_NAME"`
+	Color string `xml:"LINE_COLOR"`
+}
+
+type vehicleDataXML struct {
+	XMLName xml.Name          `xml:"TRAINS"`
+	Trains  []vehicleTrainXML `xml:"TRAIN"`
+}
+
+type vehicleTrainXML struct {
+	ID                 string `xml:"ID"`
+	Line               string `xml:"TRAIN_LINE"`
+	Direction          string `xml:"DIRECTION"`
+	TrackCircuit       string `xml:"ICS_TRACK_CKT"`
+	LastModified       string `xml:"LAST_MODIFIED"`
+	ScheduledDeparture string `xml:"SCHED_DEP_TIME"`
+	SecondsLate        int    `xml:"SEC_LATE"`
+	NextStop           string `xml:"NEXT_STOP"`
+	Longitude          string `xml:"LONGITUDE"`
+	Latitude           string `xml:"LATITUDE"`
+}
+
+// renderer turns the server's data into API payloads.
+type renderer struct {
+	loc *time.Location
+}
+
+func (r renderer) time(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(r.loc).Format(timeLayout)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "YES"
+	}
+	return "NO"
+}
+
+func latLng(p *Position) (lat, lng string) {
+	if p == nil {
+		return "", ""
+	}
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64), strconv.FormatFloat(p.Lng, 'f', -1, 64)
+}
+
+func (r renderer) stationList(stations []Station) any {
+	out := stationListXML{}
+	for _, s := range stations {
+		out.Stations = append(out.Stations, stationItemXML{Code: s.Code, Name: s.Name})
+	}
+	return out
+}
+
+// stationData renders the departure board for a station: every train with
+// a stop at the station which it has not yet departed, in departure order.
+func (r renderer) stationData(station Station, trains []*Train) any {
+	type departure struct {
+		train *Train
+		stop  Stop
+	}
+	var deps []departure
+	for _, t := range trains {
+		for _, s := range t.Stops {
+			if s.Code == station.Code && !s.Departed {
+				deps = append(deps, departure{t, s})
+				break
+			}
+		}
+	}
+	sort.SliceStable(deps, func(i, j int) bool {
+		return deps[i].stop.departureTime().Before(deps[j].stop.departureTime())
+	})
+
+	out := stationDataXML{Code: station.Code, Name: station.Name}
+	for _, b := range station.Banners {
+		out.Banners = append(out.Banners, bannerXML{
+			ID: b.ID, Type: b.Type, Text: b.Text, Start: r.time(b.Start), End: r.time(b.End),
+		})
+	}
+	for i, d := range deps {
+		lat, lng := latLng(d.train.Position)
+		item := stationDataItemXML{
+			Index:              i,
+			ScheduledDeparture: r.time(d.stop.departureTime()),
+			Destination:        d.train.destination(),
+			Track:              d.stop.Track,
+			Line:               d.train.Line,
+			TrainID:            d.train.ID,
+			Status:             d.train.Status,
+			SecondsLate:        int(d.train.Delay / time.Second),
+			LastModified:       r.time(d.train.LastModified),
+			BackColor:          d.train.Color,
+			ForeColor:          "white",
+			ShadowColor:        "black",
+			Latitude:           lat,
+			Longitude:          lng,
+			GPSTime:            r.time(d.train.LastModified),
+			StationPosition:    1,
+			LineAbbreviation:   d.train.LineCode,
+		}
+		for _, s := range d.train.Stops {
+			item.Stops = append(item.Stops, stationDataStopXML{
+				Name: s.Name, Time: r.time(s.Time), Departed: yesNo(s.Departed), Status: s.Status,
+			})
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out
+}
+
+// trainMap renders the map payload. Like the real API, it returns a bare
+// train when the ID is unknown.
+func (r renderer) trainMap(id string, t *Train) any {
+	if t == nil {
+		return trainMapXML{Trains: []trainMapTrain{{ID: id}}}
+	}
+	lat, lng := latLng(t.Position)
+	return trainMapXML{Trains: []trainMapTrain{{
+		ID:           t.ID,
+		Direction:    t.Direction,
+		Line:         t.Line,
+		LastModified: r.time(t.LastModified),
+		BackColor:    t.Color,
+		TrackCircuit: t.TrackCircuit,
+		Latitude:     lat,
+		Longitude:    lng,
+	}}}
+}
+
+// trainStops renders the stop list payload, which has an empty ID when the
+// train is unknown.
+func (r renderer) trainStops(t *Train) any {
+	if t == nil {
+		return trainStopsXML{}
+	}
+	lat, lng := latLng(t.Position)
+	out := trainStopsXML{
+		ID:          t.ID,
+		Destination: t.destination(),
+		GPSTime:     r.time(t.LastModified),
+		Latitude:    lat,
+		Longitude:   lng,
+	}
+	for _, s := range t.Stops {
+		stop := trainStopXML{
+			Name:          s.Name,
+			Code:          s.Code,
+			Time:          r.time(s.Time),
+			Departed:      yesNo(s.Departed),
+			Status:        s.Status,
+			DepartureTime: r.time(s.departureTime()),
+		}
+		for _, l := range s.Lines {
+			stop.Lines = append(stop.Lines, stopLineXML(l))
+		}
+		out.Stops = append(out.Stops, stop)
+	}
+	return out
+}
+
+func (r renderer) vehicleData(trains []*Train) any {
+	out := vehicleDataXML{}
+	for _, t := range trains {
+		lat, lng := latLng(t.Position)
+		out.Trains = append(out.Trains, vehicleTrainXML{
+			ID:                 t.ID,
+			Line:               t.Line,
+			Direction:          t.Direction,
+			TrackCircuit:       t.TrackCircuit,
+			LastModified:       r.time(t.LastModified),
+			ScheduledDeparture: r.time(t.scheduledDeparture()),
+			SecondsLate:        int(t.Delay / time.Second),
+			NextStop:           t.nextStop(),
+			Latitude:           lat,
+			Longitude:          lng,
+		})
+	}
+	return out
+}
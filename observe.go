@@ -0,0 +1,149 @@
+package njtapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CallInfo describes a call to one of the client's public methods.
+type CallInfo struct {
+	Method   string  // Public method, like "StationData"
+	Endpoint string  // API endpoint backing the method, like "getTrainScheduleXML", or "" for GetTrain
+	Station  string  // Station code, if the method takes one
+	TrainID  TrainID // Train ID, if the method takes one
+}
+
+// CallResult describes the outcome of a call to one of the client's public
+// methods. The HTTP details of a method which calls others, like GetTrain,
+// cover every request made by those calls.
+type CallResult struct {
+	Err           error         // Error returned to the caller
+	StatusCode    int           // Status of the last HTTP response, or 0 if none was received
+	ResponseBytes int           // Total size of all HTTP response bodies
+	ParseErrors   int           // Number of ParseErrors attached to the results
+	Duration      time.Duration // Time taken by the call
+}
+
+// An Observer is notified of every call to the client's public methods,
+// which makes it a natural place to hang tracing spans and metrics without
+// the client depending on any particular telemetry library.
+//
+// See the otelnjtapi package for an OpenTelemetry implementation.
+type Observer interface {
+	// StartCall is invoked when a call begins. The returned context is used
+	// for the rest of the call and the returned function is invoked once
+	// with its outcome.
+	StartCall(ctx context.Context, info CallInfo) (context.Context, func(CallResult))
+}
+
+// callStatsKey is the context key for the current call's callStats.
+type callStatsKey struct{}
+
+// callStats accumulates HTTP details of a call for its Observer.
+type callStats struct {
+	mu            sync.Mutex
+	statusCode    int
+	responseBytes int
+}
+
+// recordResponse notes an HTTP response against the call in ctx, if any.
+func recordResponse(ctx context.Context, resp *Response) {
+	stats, ok := ctx.Value(callStatsKey{}).(*callStats)
+	if !ok || resp == nil {
+		return
+	}
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.statusCode = resp.StatusCode
+	stats.responseBytes += len(resp.Body)
+}
+
+// addCallStats adds the details of a shared call to the call in ctx, if any.
+func addCallStats(ctx context.Context, shared *callStats) {
+	stats, ok := ctx.Value(callStatsKey{}).(*callStats)
+	if !ok {
+		return
+	}
+	shared.mu.Lock()
+	statusCode, responseBytes := shared.statusCode, shared.responseBytes
+	shared.mu.Unlock()
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	if statusCode != 0 {
+		stats.statusCode = statusCode
+	}
+	stats.responseBytes += responseBytes
+}
+
+// invoke runs fn on behalf of a public method, applying the call timeout,
+// reporting it to the client's Observer and coalescing it with identical
+// concurrent calls.
+func invoke[T any](ctx context.Context, c *Client, info CallInfo, params map[string]string, fn func(context.Context) (T, error)) (T, error) {
+	if c.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.callTimeout)
+		defer cancel()
+	}
+	key := cacheKey(info.Endpoint, params)
+	if c.observer == nil {
+		return coalesce(ctx, c, key, fn)
+	}
+
+	start := time.Now()
+	parent := ctx
+	stats := &callStats{}
+	ctx, finish := c.observer.StartCall(context.WithValue(ctx, callStatsKey{}, stats), info)
+	// Calls made on behalf of another, like GetTrain, also count towards it.
+	defer addCallStats(parent, stats)
+
+	v, err := coalesce(ctx, c, key, fn)
+
+	stats.mu.Lock()
+	res := CallResult{
+		Err:           err,
+		StatusCode:    stats.statusCode,
+		ResponseBytes: stats.responseBytes,
+		ParseErrors:   countParseErrors(v),
+		Duration:      time.Since(start),
+	}
+	stats.mu.Unlock()
+	finish(res)
+	return v, err
+}
+
+// countParseErrors totals the ParseErrors attached to a method's results.
+func countParseErrors(v any) int {
+	n := 0
+	switch v := v.(type) {
+	case *Station:
+		if v != nil {
+			for _, b := range v.Banners {
+				n += len(b.ParseErrors)
+			}
+			for _, t := range v.Departures {
+				n += len(t.ParseErrors)
+				for _, s := range t.Stops {
+					n += len(s.ParseErrors)
+				}
+			}
+		}
+	case *Train:
+		if v != nil {
+			n += countParseErrors([]Train{*v})
+		}
+	case *MergedTrain:
+		if v != nil {
+			n += countParseErrors([]Train{v.Train})
+		}
+	case []Train:
+		for _, t := range v {
+			n += len(t.ParseErrors)
+			for _, s := range t.Stops {
+				n += len(s.ParseErrors)
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,211 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// recordingObserver keeps every call it is notified of.
+type recordingObserver struct {
+	mu      sync.Mutex
+	infos   []CallInfo
+	parents []any // Method of the enclosing call, if any
+	results []CallResult
+}
+
+type observerKey struct{}
+
+func (o *recordingObserver) StartCall(ctx context.Context, info CallInfo) (context.Context, func(CallResult)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.infos = append(o.infos, info)
+	o.parents = append(o.parents, ctx.Value(observerKey{}))
+	return context.WithValue(ctx, observerKey{}, info.Method), func(r CallResult) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.results = append(o.results, r)
+	}
+}
+
+func TestObserver(t *testing.T) {
+	schedule, err := os.ReadFile("testdata/getTrainSchedule1.xml")
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+
+	vehicles := []byte(`<TRAINS><TRAIN><ID>1</ID><LAST_MODIFIED>bad</LAST_MODIFIED><SCHED_DEP_TIME>bad</SCHED_DEP_TIME></TRAIN></TRAINS>`)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + stationDataEndpoint:
+			_, _ = w.Write(schedule)
+		case "/" + vehicleDataEndpoint:
+			_, _ = w.Write(vehicles)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	o := &recordingObserver{}
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetObserver(o)
+	c.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			if ctx.Value(observerKey{}) == nil {
+				t.Errorf("%s: context from the Observer was not propagated", req.Endpoint)
+			}
+			return next(ctx, req)
+		}
+	})
+
+	ctx := context.Background()
+	if _, err := c.StationData(ctx, "SE"); err != nil {
+		t.Fatalf("StationData() unexpected error: %v", err)
+	}
+	if _, err := c.VehicleData(ctx); err != nil {
+		t.Fatalf("VehicleData() unexpected error: %v", err)
+	}
+	_, trainErr := c.GetTrainMap(ctx, NJTTrainID(3874))
+	if trainErr == nil {
+		t.Fatal("GetTrainMap() expected error, got none")
+	}
+
+	wantInfos := []CallInfo{
+		{Method: "StationData", Endpoint: stationDataEndpoint, Station: "SE"},
+		{Method: "VehicleData", Endpoint: vehicleDataEndpoint},
+		{Method: "GetTrainMap", Endpoint: trainMapEndpoint, TrainID: NJTTrainID(3874)},
+	}
+	if diff := cmp.Diff(wantInfos, o.infos); diff != "" {
+		t.Errorf("CallInfo mismatch (-want +got):\n%s", diff)
+	}
+
+	wantResults := []CallResult{
+		{StatusCode: 200, ResponseBytes: len(schedule)},
+		{StatusCode: 200, ResponseBytes: len(vehicles), ParseErrors: 2},
+		{StatusCode: 404, Err: trainErr},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreFields(CallResult{}, "Duration"),
+		cmp.Comparer(func(a, b error) bool { return errors.Is(a, b) }),
+	}
+	if diff := cmp.Diff(wantResults, o.results, opts...); diff != "" {
+		t.Errorf("CallResult mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestObserverCoalesced(t *testing.T) {
+	schedule, err := os.ReadFile("testdata/getTrainSchedule1.xml")
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	var calls int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		_, _ = w.Write(schedule)
+	}))
+	defer ts.Close()
+
+	o := &recordingObserver{}
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetObserver(o)
+	c.SetCoalescing(true)
+
+	const n = 5
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.StationData(context.Background(), "SE"); err != nil {
+				t.Errorf("StationData() unexpected error: %v", err)
+			}
+		}()
+	}
+	waitForWaiters(t, c, cacheKey(stationDataEndpoint, map[string]string{"station": "SE"}), n)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if len(o.results) != n {
+		t.Fatalf("expected %d results, got %d", n, len(o.results))
+	}
+	for i, r := range o.results {
+		// Every caller reports the shared response, not just the first.
+		if r.StatusCode != http.StatusOK || r.ResponseBytes != len(schedule) {
+			t.Errorf("result #%d got status %d, %d bytes want %d, %d", i, r.StatusCode, r.ResponseBytes, http.StatusOK, len(schedule))
+		}
+	}
+}
+
+func TestObserverGetTrain(t *testing.T) {
+	vehicles := []byte(`<TRAINS><TRAIN><ID>1</ID><LAST_MODIFIED>bad</LAST_MODIFIED><SCHED_DEP_TIME>bad</SCHED_DEP_TIME></TRAIN></TRAINS>`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+vehicleDataEndpoint {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(vehicles)
+	}))
+	defer ts.Close()
+
+	o := &recordingObserver{}
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetObserver(o)
+
+	if _, err := c.GetTrain(context.Background(), NJTTrainID(1)); err != nil {
+		t.Fatalf("GetTrain() unexpected error: %v", err)
+	}
+
+	if len(o.infos) != 4 || o.infos[0] != (CallInfo{Method: "GetTrain", TrainID: NJTTrainID(1)}) || o.parents[0] != nil {
+		t.Fatalf("expected GetTrain to be observed first, got %+v", o.infos)
+	}
+	for i, info := range o.infos[1:] {
+		if o.parents[i+1] != "GetTrain" {
+			t.Errorf("%s was not called within GetTrain", info.Method)
+		}
+	}
+	// GetTrain finishes last and covers the requests of its sources.
+	got := o.results[len(o.results)-1]
+	if got.ResponseBytes != len(vehicles) || got.ParseErrors != 2 || got.Err != nil {
+		t.Errorf("GetTrain CallResult got %+v want %d bytes and 2 parse errors", got, len(vehicles))
+	}
+}
+
+func TestCountParseErrors(t *testing.T) {
+	e := errors.New("bad")
+	train := Train{
+		ParseErrors: []error{e},
+		Stops:       []StationStop{{ParseErrors: []error{e, e}}},
+	}
+	for _, r := range []struct {
+		name string
+		v    any
+		want int
+	}{
+		{"nil station", (*Station)(nil), 0},
+		{"station", &Station{Departures: []StationTrain{{ParseErrors: []error{e}, Stops: []StationStop{{ParseErrors: []error{e}}}}}}, 2},
+		{"train", &train, 3},
+		{"nil merged train", (*MergedTrain)(nil), 0},
+		{"merged train", &MergedTrain{Train: train}, 3},
+		{"trains", []Train{train, train}, 6},
+		{"stations", []Station{{}}, 0},
+	} {
+		if got := countParseErrors(r.v); got != r.want {
+			t.Errorf("countParseErrors(%s) got %d want %d", r.name, got, r.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package njtapi
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// An Option configures a Client built by New.
+type Option func(*Client)
+
+// New constructs a client for the API rooted at baseURL.
+//
+// Without options, the client uses an http.Client with a 30 second timeout,
+// parses times in America/New_York (falling back to UTC), and has no
+// credentials, so every call fails with ErrMissingCredentials until
+// WithCredentials or WithBasicAuth is supplied.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    baseURL,
+		location:   defaultLocation(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithHTTPClient uses hc when talking to the API, e.g. to configure a proxy
+// server or transport. A nil hc keeps the default.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// WithLocation sets the timezone API timestamps are parsed in.
+// A nil loc falls back to UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(c *Client) {
+		if loc == nil {
+			loc = time.UTC
+		}
+		c.location = loc
+	}
+}
+
+// WithCredentials sets the provider consulted for credentials before every
+// request. See SetCredentials.
+func WithCredentials(p CredentialsProvider) Option {
+	return func(c *Client) { c.SetCredentials(p) }
+}
+
+// WithBasicAuth authenticates with a fixed username and password.
+func WithBasicAuth(username, password string) Option {
+	return WithCredentials(StaticCredentials{Username: username, Password: password})
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// WithRetryPolicy configures how failed requests are retried.
+// See SetRetryPolicy.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(c *Client) { c.SetRetryPolicy(p) }
+}
+
+// WithCache configures caching of API responses. See SetCache.
+func WithCache(p *CachePolicy) Option {
+	return func(c *Client) { c.SetCache(p) }
+}
+
+// WithLogger configures structured logging. See SetLogger.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) { c.SetLogger(l) }
+}
+
+// WithLimiter configures a Limiter consulted before every request.
+// See SetLimiter.
+func WithLimiter(l Limiter) Option {
+	return func(c *Client) { c.SetLimiter(l) }
+}
+
+// WithRequestTimeout bounds each HTTP request, including each retry
+// attempt individually. Zero, the default, leaves requests bounded only by
+// the http.Client and the caller's context.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(c *Client) { c.requestTimeout = d }
+}
+
+// WithCallTimeout bounds each call to a public method as a whole, including
+// retries, backoff and token refreshes. Zero, the default, leaves calls
+// bounded only by the caller's context.
+func WithCallTimeout(d time.Duration) Option {
+	return func(c *Client) { c.callTimeout = d }
+}
+
+// WithCoalescing controls sharing of concurrent identical requests.
+// See SetCoalescing.
+func WithCoalescing(enabled bool) Option {
+	return func(c *Client) { c.SetCoalescing(enabled) }
+}
+
+// WithAuthMode selects how the client authenticates. See SetAuthMode.
+func WithAuthMode(m AuthMode) Option {
+	return func(c *Client) { c.SetAuthMode(m) }
+}
+
+// WithFormat selects how responses are decoded. See SetFormat.
+func WithFormat(f Format) Option {
+	return func(c *Client) { c.SetFormat(f) }
+}
+
+// WithObserver configures an Observer notified of every public method call.
+// See SetObserver.
+func WithObserver(o Observer) Option {
+	return func(c *Client) { c.SetObserver(o) }
+}
+
+// WithMiddleware appends middleware to the client. See Use.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) { c.Use(mw...) }
+}
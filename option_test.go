@@ -0,0 +1,119 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	hc := &http.Client{}
+	retry := DefaultRetryPolicy()
+	cache := &CachePolicy{Cache: NewLRUCache(10)}
+	limiter := NewQuotaLimiter(QuotaConfig{})
+	c := New("http://example.com/",
+		WithHTTPClient(hc),
+		WithLocation(time.UTC),
+		WithBasicAuth("user", "pass"),
+		WithUserAgent("njtapi-test"),
+		WithRetryPolicy(retry),
+		WithCache(cache),
+		WithLimiter(limiter),
+		WithRequestTimeout(time.Second),
+		WithCallTimeout(time.Minute),
+		WithCoalescing(true),
+		WithAuthMode(AuthToken),
+		WithFormat(FormatJSON),
+	)
+
+	if c.httpClient != hc {
+		t.Error("WithHTTPClient() not applied")
+	}
+	if c.location != time.UTC {
+		t.Errorf("location got %v want UTC", c.location)
+	}
+	if c.creds != (StaticCredentials{Username: "user", Password: "pass"}) {
+		t.Errorf("creds got %v", c.creds)
+	}
+	if c.retry != retry || c.cache != cache || c.limiter != limiter {
+		t.Error("retry, cache or limiter option not applied")
+	}
+	if c.userAgent != "njtapi-test" || c.requestTimeout != time.Second || c.callTimeout != time.Minute {
+		t.Errorf("got user agent %q, timeouts %v/%v", c.userAgent, c.requestTimeout, c.callTimeout)
+	}
+	if !c.coalesce || c.authMode != AuthToken || c.format != FormatJSON {
+		t.Error("coalescing, auth mode or format option not applied")
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	c := New("http://example.com/", WithHTTPClient(nil), WithLocation(nil))
+	if c.httpClient == nil || c.httpClient.Timeout != 30*time.Second {
+		t.Errorf("httpClient got %+v want 30s timeout", c.httpClient)
+	}
+	if c.location != time.UTC {
+		t.Errorf("WithLocation(nil) got %v want UTC", c.location)
+	}
+	if _, err := c.StationList(context.Background()); !errors.Is(err, ErrMissingCredentials) {
+		t.Errorf("StationList() without credentials got %v want ErrMissingCredentials", err)
+	}
+}
+
+func TestLegacyConstructors(t *testing.T) {
+	hc := &http.Client{}
+	for _, r := range []struct {
+		name string
+		c    *Client
+		hc   *http.Client
+		loc  *time.Location
+	}{
+		{"NewClient", NewClient("u", "user", "pass"), nil, defaultLocation()},
+		{"NewClientWithLocation", NewClientWithLocation("u", "user", "pass", time.UTC), nil, time.UTC},
+		{"NewClientWithLocation nil", NewClientWithLocation("u", "user", "pass", nil), nil, time.UTC},
+		{"NewCustomClient", NewCustomClient(hc, "u", "user", "pass"), hc, defaultLocation()},
+	} {
+		if r.c.baseURL != "u" || r.c.creds != (StaticCredentials{Username: "user", Password: "pass"}) {
+			t.Errorf("%s: got baseURL %q creds %v", r.name, r.c.baseURL, r.c.creds)
+		}
+		if r.c.location.String() != r.loc.String() {
+			t.Errorf("%s: location got %v want %v", r.name, r.c.location, r.loc)
+		}
+		if r.hc != nil && r.c.httpClient != r.hc {
+			t.Errorf("%s: custom http.Client not used", r.name)
+		}
+	}
+}
+
+func TestUserAgentAndTimeouts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("User-Agent"); got != "departure-board/1.0" {
+			t.Errorf("User-Agent got %q", got)
+		}
+		if r.URL.Query().Get("station") == "SLOW" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		http.ServeFile(w, r, "testdata/getTrainSchedule1.xml")
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL, WithBasicAuth("user", "pass"), WithUserAgent("departure-board/1.0"),
+		WithRequestTimeout(20*time.Millisecond))
+	if _, err := c.StationData(context.Background(), "NY"); err != nil {
+		t.Fatalf("StationData() unexpected error: %v", err)
+	}
+	if _, err := c.StationData(context.Background(), "SLOW"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StationData() with request timeout got %v want DeadlineExceeded", err)
+	}
+
+	c = New(ts.URL, WithBasicAuth("user", "pass"), WithUserAgent("departure-board/1.0"),
+		WithCallTimeout(20*time.Millisecond))
+	if _, err := c.StationData(context.Background(), "SLOW"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StationData() with call timeout got %v want DeadlineExceeded", err)
+	}
+}
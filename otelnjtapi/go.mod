@@ -0,0 +1,27 @@
+module github.com/bamnet/njtapi/otelnjtapi
+
+// OpenTelemetry v1.46 requires Go 1.25; the core njtapi module only needs 1.22.
+go 1.25.0
+
+require (
+	github.com/bamnet/njtapi v0.1.0
+	go.opentelemetry.io/otel v1.46.0
+	go.opentelemetry.io/otel/metric v1.46.0
+	go.opentelemetry.io/otel/sdk v1.46.0
+	go.opentelemetry.io/otel/sdk/metric v1.46.0
+	go.opentelemetry.io/otel/trace v1.46.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+)
+
+// Build against the enclosing checkout during development. Importers ignore
+// replace directives, so njtapi v0.1.0, the first release with Observer and
+// CallInfo, must be tagged before otelnjtapi itself is tagged and published.
+replace github.com/bamnet/njtapi => ../
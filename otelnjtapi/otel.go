@@ -0,0 +1,136 @@
+// Package otelnjtapi reports njtapi client calls to OpenTelemetry.
+//
+// It lives in its own module so that the njtapi package itself does not
+// depend on OpenTelemetry.
+//
+//	client := njtapi.NewClient(baseURL, username, password)
+//	client.SetObserver(otelnjtapi.NewObserver())
+package otelnjtapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bamnet/njtapi"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scope is the instrumentation scope name used for tracers and meters.
+const scope = "github.com/bamnet/njtapi/otelnjtapi"
+
+// Attribute keys set on spans and metrics.
+const (
+	MethodKey        = attribute.Key("njtapi.method")
+	EndpointKey      = attribute.Key("njtapi.endpoint")
+	StationKey       = attribute.Key("njtapi.station")
+	TrainIDKey       = attribute.Key("njtapi.train_id")
+	ResponseBytesKey = attribute.Key("njtapi.response_bytes")
+	ParseErrorsKey   = attribute.Key("njtapi.parse_errors")
+	StatusCodeKey    = attribute.Key("http.response.status_code")
+)
+
+// An Option configures an Observer.
+type Option func(*config)
+
+type config struct {
+	tp trace.TracerProvider
+	mp metric.MeterProvider
+}
+
+// WithTracerProvider sets the TracerProvider spans are created with.
+// It defaults to the global TracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) { c.tp = tp }
+}
+
+// WithMeterProvider sets the MeterProvider metrics are recorded with.
+// It defaults to the global MeterProvider.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) { c.mp = mp }
+}
+
+// Observer is an njtapi.Observer which creates a span per call and records
+// call duration, errors and parse errors as metrics.
+type Observer struct {
+	tracer      trace.Tracer
+	duration    metric.Float64Histogram
+	errors      metric.Int64Counter
+	parseErrors metric.Int64Counter
+}
+
+var _ njtapi.Observer = (*Observer)(nil)
+
+// NewObserver returns an Observer using the global providers unless
+// overridden by opts.
+func NewObserver(opts ...Option) *Observer {
+	cfg := config{tp: otel.GetTracerProvider(), mp: otel.GetMeterProvider()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.mp.Meter(scope)
+	o := &Observer{tracer: cfg.tp.Tracer(scope)}
+	// Instrument creation only fails for invalid names or units, and the
+	// returned instruments are usable no-ops even then.
+	o.duration, _ = meter.Float64Histogram("njtapi.call.duration",
+		metric.WithDescription("Duration of njtapi client calls."),
+		metric.WithUnit("s"))
+	o.errors, _ = meter.Int64Counter("njtapi.call.errors",
+		metric.WithDescription("Number of njtapi client calls which returned an error."),
+		metric.WithUnit("{call}"))
+	o.parseErrors, _ = meter.Int64Counter("njtapi.parse_errors",
+		metric.WithDescription("Number of fields which could not be parsed from API responses."),
+		metric.WithUnit("{error}"))
+	return o
+}
+
+// StartCall implements njtapi.Observer.
+func (o *Observer) StartCall(ctx context.Context, info njtapi.CallInfo) (context.Context, func(njtapi.CallResult)) {
+	attrs := []attribute.KeyValue{
+		MethodKey.String(info.Method),
+		EndpointKey.String(info.Endpoint),
+	}
+	spanAttrs := attrs
+	if info.Station != "" {
+		spanAttrs = append(spanAttrs, StationKey.String(info.Station))
+	}
+	if id := info.TrainID.String(); id != "" {
+		spanAttrs = append(spanAttrs, TrainIDKey.String(id))
+	}
+
+	ctx, span := o.tracer.Start(ctx, "njtapi."+info.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(spanAttrs...))
+
+	return ctx, func(r njtapi.CallResult) {
+		defer span.End()
+
+		span.SetAttributes(
+			ResponseBytesKey.Int(r.ResponseBytes),
+			ParseErrorsKey.Int(r.ParseErrors))
+		if r.StatusCode != 0 {
+			span.SetAttributes(StatusCodeKey.Int(r.StatusCode))
+		}
+		if r.Err != nil {
+			span.RecordError(r.Err)
+			span.SetStatus(codes.Error, r.Err.Error())
+		} else if r.ParseErrors > 0 {
+			span.AddEvent(fmt.Sprintf("%d fields could not be parsed", r.ParseErrors))
+		}
+
+		// Metrics deliberately omit station and train attributes to keep
+		// cardinality bounded.
+		set := metric.WithAttributes(attrs...)
+		o.duration.Record(ctx, r.Duration.Seconds(), set)
+		if r.Err != nil {
+			o.errors.Add(ctx, 1, set)
+		}
+		if r.ParseErrors > 0 {
+			o.parseErrors.Add(ctx, int64(r.ParseErrors), set)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package otelnjtapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bamnet/njtapi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestObserver(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("station") {
+		case "NY":
+			http.ServeFile(w, r, "../testdata/getTrainSchedule1.xml")
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	c := njtapi.NewClient(ts.URL, "username", "password")
+	c.SetObserver(NewObserver(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	))
+
+	ctx := context.Background()
+	if _, err := c.StationData(ctx, "NY"); err != nil {
+		t.Fatalf("StationData() unexpected error: %v", err)
+	}
+	if _, err := c.StationData(ctx, "XX"); err == nil {
+		t.Fatal("StationData() expected error, got none")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("got %d spans want 2", len(ended))
+	}
+	for i, r := range []struct {
+		station string
+		status  int
+		code    codes.Code
+	}{
+		{"NY", 200, codes.Unset},
+		{"XX", 500, codes.Error},
+	} {
+		s := ended[i]
+		if s.Name() != "njtapi.StationData" {
+			t.Errorf("span %d name got %q want njtapi.StationData", i, s.Name())
+		}
+		attrs := attribute.NewSet(s.Attributes()...)
+		if v, _ := attrs.Value(StationKey); v.AsString() != r.station {
+			t.Errorf("span %d %s got %q want %q", i, StationKey, v.AsString(), r.station)
+		}
+		if v, _ := attrs.Value(StatusCodeKey); v.AsInt64() != int64(r.status) {
+			t.Errorf("span %d %s got %d want %d", i, StatusCodeKey, v.AsInt64(), r.status)
+		}
+		if v, _ := attrs.Value(EndpointKey); v.AsString() != "getTrainScheduleXML" {
+			t.Errorf("span %d %s got %q", i, EndpointKey, v.AsString())
+		}
+		if s.Status().Code != r.code {
+			t.Errorf("span %d status got %v want %v", i, s.Status().Code, r.code)
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	got := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			got[m.Name] = true
+			switch d := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				if n := d.DataPoints[0].Count; n != 2 {
+					t.Errorf("%s count got %d want 2", m.Name, n)
+				}
+			case metricdata.Sum[int64]:
+				if m.Name == "njtapi.call.errors" && d.DataPoints[0].Value != 1 {
+					t.Errorf("%s got %d want 1", m.Name, d.DataPoints[0].Value)
+				}
+			}
+		}
+	}
+	for _, name := range []string{"njtapi.call.duration", "njtapi.call.errors"} {
+		if !got[name] {
+			t.Errorf("metric %s not recorded", name)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package njtapi
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redactedValue replaces secrets in URLs and errors.
+const redactedValue = "REDACTED"
+
+// secretParams are query / form parameters which must never be exposed.
+var secretParams = []string{"username", "password", "token"}
+
+func isSecretParam(k string) bool {
+	for _, p := range secretParams {
+		if k == p {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL returns rawURL with every secret query parameter replaced.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redactedValue
+	}
+	q := u.Query()
+	changed := false
+	for _, k := range secretParams {
+		if q.Has(k) {
+			q.Set(k, redactedValue)
+			changed = true
+		}
+	}
+	if u.User != nil {
+		u.User = url.User(redactedValue)
+		changed = true
+	}
+	if !changed {
+		return rawURL
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// secretParamPattern matches a secret parameter and its value wherever it
+// appears in text, e.g. in a URL quoted by an error or echoed in a body.
+var secretParamPattern = regexp.MustCompile(`\b(username|password|token)=[^&\s"'<>]*`)
+
+// minSecretLen is the shortest secret scrubbed wherever it appears. Shorter
+// values would mangle unrelated text and reveal little if left in place.
+const minSecretLen = 4
+
+// secrets are credential values which must not appear in errors. They
+// should be values, like passwords and tokens, which are never expected in
+// ordinary text; usernames are only scrubbed where they appear as parameters.
+type secrets []string
+
+// redact masks secret parameters in text, then replaces every secret,
+// including URL-escaped forms.
+func (s secrets) redact(text string) string {
+	text = secretParamPattern.ReplaceAllString(text, "${1}="+redactedValue)
+	for _, v := range s {
+		if len(v) < minSecretLen {
+			continue
+		}
+		for _, form := range []string{v, url.QueryEscape(v), url.PathEscape(v)} {
+			text = strings.ReplaceAll(text, form, redactedValue)
+		}
+	}
+	return text
+}
+
+// redactError scrubs secrets from err and every error it wraps. Errors
+// are rebuilt rather than modified, so the caller's errors are left alone.
+// A *url.Error or *APIError keeps its type so errors.As keeps working; any
+// other error whose message contains a secret is replaced by one with the
+// secret masked, wrapping the redacted form of whatever it wrapped.
+func (s secrets) redactError(err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *url.Error:
+		return &url.Error{Op: e.Op, URL: s.redact(redactURL(e.URL)), Err: s.redactError(e.Err)}
+	case *APIError:
+		redacted := *e
+		redacted.Body = s.redact(e.Body)
+		return &redacted
+	}
+	if msg := err.Error(); s.redact(msg) != msg {
+		r := &redactedError{msg: s.redact(msg)}
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			if inner := e.Unwrap(); inner != nil {
+				r.errs = []error{s.redactError(inner)}
+			}
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				r.errs = append(r.errs, s.redactError(inner))
+			}
+		}
+		return r
+	}
+	return err
+}
+
+// redactedError stands in for an error whose message contained a secret.
+type redactedError struct {
+	msg  string
+	errs []error // Redacted forms of the errors it wrapped
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() []error { return e.errs }
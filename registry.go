@@ -0,0 +1,40 @@
+package njtapi
+
+import "strings"
+
+// StationIDSource records how a StationStop's StationID was determined.
+type StationIDSource int
+
+// Sources of a StationStop's StationID.
+const (
+	StationIDUnresolved   StationIDSource = iota // No ID was sent and the name is not known
+	StationIDFromFeed                            // Sent by the API as STATION_2CHAR
+	StationIDFromRegistry                        // Looked up from the stop name
+)
+
+func (s StationIDSource) String() string {
+	switch s {
+	case StationIDFromFeed:
+		return "feed"
+	case StationIDFromRegistry:
+		return "registry"
+	}
+	return "unresolved"
+}
+
+// normalizeName lowercases a name and collapses its whitespace.
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// resolveStationID sets a stop's StationID from the code sent by the API,
+// or failing that, by looking up its name.
+func resolveStationID(stop *StationStop, code string) {
+	if code = strings.TrimSpace(code); code != "" {
+		stop.StationID, stop.StationIDSource = code, StationIDFromFeed
+		return
+	}
+	if info, ok := stations.byName[normalizeName(stop.Name)]; ok {
+		stop.StationID, stop.StationIDSource = info.Code, StationIDFromRegistry
+	}
+}
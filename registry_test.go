@@ -0,0 +1,24 @@
+package njtapi
+
+import "testing"
+
+func TestResolveStationID(t *testing.T) {
+	for _, r := range []struct {
+		name, code string
+		wantID     string
+		wantSource StationIDSource
+	}{
+		{"Hoboken", "HB", "HB", StationIDFromFeed},
+		{"Secaucus Upper Lvl", " TS\n", "TS", StationIDFromFeed},
+		{"Secaucus Upper Lvl", "", "SE", StationIDFromRegistry},
+		{"secaucus  LOWER lvl", "", "TS", StationIDFromRegistry},
+		{"Nowhere Junction", "", "", StationIDUnresolved},
+	} {
+		stop := StationStop{Name: r.name}
+		resolveStationID(&stop, r.code)
+		if stop.StationID != r.wantID || stop.StationIDSource != r.wantSource {
+			t.Errorf("resolveStationID(%q, %q) got %q from %v want %q from %v",
+				r.name, r.code, stop.StationID, stop.StationIDSource, r.wantID, r.wantSource)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrStationNotFound is returned when no station matches a query.
+var ErrStationNotFound = errors.New("station not found")
+
+// ErrAmbiguousStation is returned when a query matches several stations
+// equally well.
+var ErrAmbiguousStation = errors.New("ambiguous station")
+
+// AmbiguousStationError lists the stations a query could refer to.
+type AmbiguousStationError struct {
+	Query   string         // Query as given
+	Matches []StationMatch // Equally good matches, best first
+}
+
+func (e *AmbiguousStationError) Error() string {
+	names := make([]string, len(e.Matches))
+	for i, m := range e.Matches {
+		names[i] = fmt.Sprintf("%s (%s)", m.Name, m.Code)
+	}
+	return fmt.Sprintf("%q could be %s", e.Query, strings.Join(names, " or "))
+}
+
+func (e *AmbiguousStationError) Unwrap() error {
+	return ErrAmbiguousStation
+}
+
+// A StationMatch is a station that matches a query.
+type StationMatch struct {
+	Code    string  // Station character code, like "SE"
+	Name    string  // Distinguishing name of the station, like "Secaucus Upper Lvl"
+	Matched string  // Name or alias the query matched
+	Score   float64 // Match quality from 0 to 1, where 1 is an exact match
+}
+
+const (
+	// minMatchScore is the lowest score Resolve reports.
+	minMatchScore = 0.6
+	// ambiguityMargin is how close two scores must be to count as a tie.
+	ambiguityMargin = 0.05
+	// minTokenSimilarity is the lowest edit-distance similarity for two
+	// words to be considered the same.
+	minTokenSimilarity = 0.75
+)
+
+// tokenSynonyms expands abbreviations people and the API use in station
+// names.
+var tokenSynonyms = map[string][]string{
+	"ny":   {"new", "york"},
+	"nyc":  {"new", "york"},
+	"av":   {"avenue"},
+	"ave":  {"avenue"},
+	"st":   {"street"},
+	"lvl":  {"level"},
+	"mt":   {"mount"},
+	"u":    {"university"},
+	"univ": {"university"},
+	"intl": {"international"},
+	"jct":  {"junction"},
+	"&":    {"and"},
+}
+
+// stopTokens are words that do not help tell stations apart.
+var stopTokens = map[string]bool{"station": true, "the": true}
+
+// resolverName is one name of a station, prepared for matching.
+type resolverName struct {
+	code    string
+	display string
+	name    string
+	tokens  []string
+	compact string
+}
+
+// A StationResolver turns station names as people type them, like
+// "NY Penn" or "newark airprt", into station codes.
+type StationResolver struct {
+	names []resolverName
+}
+
+// NewStationResolver returns a resolver over the given stations, usually
+// the result of StationList, along with every name the embedded station
+// dataset knows for them. If stations is empty, the whole dataset is used.
+func NewStationResolver(stations []Station) *StationResolver {
+	if len(stations) == 0 {
+		for _, info := range Stations() {
+			stations = append(stations, Station{ID: info.Code, Name: info.Name})
+		}
+	}
+	r := &StationResolver{}
+	for _, s := range stations {
+		names := append([]string{s.Name}, s.Aliases...)
+		display := strings.TrimSpace(s.Name)
+		if info, ok := LookupStation(s.ID); ok && info.Code == s.ID {
+			// The dataset names stations that share an API name, like
+			// the two Secaucus levels, apart.
+			display = info.Name
+			names = append(names, info.Name)
+			names = append(names, info.Aliases...)
+		}
+		for _, n := range names {
+			tokens := matchTokens(n)
+			if len(tokens) == 0 {
+				continue
+			}
+			r.names = append(r.names, resolverName{
+				code:    s.ID,
+				display: display,
+				name:    n,
+				tokens:  tokens,
+				compact: strings.Join(tokens, ""),
+			})
+		}
+	}
+	return r
+}
+
+// StationResolver returns a resolver over the stations returned by
+// StationList.
+func (c *Client) StationResolver(ctx context.Context) (*StationResolver, error) {
+	stations, err := c.StationList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewStationResolver(stations), nil
+}
+
+// Resolve returns the stations matching query, best first.
+func (r *StationResolver) Resolve(query string) []StationMatch {
+	q := matchTokens(query)
+	if len(q) == 0 {
+		return nil
+	}
+	code := strings.ToUpper(strings.TrimSpace(query))
+	compact := strings.Join(q, "")
+
+	best := map[string]StationMatch{}
+	for _, n := range r.names {
+		score := 0.0
+		if n.code == code {
+			score = 1
+		} else {
+			score = max(tokenScore(q, n.tokens), similarity(compact, n.compact))
+		}
+		if m, ok := best[n.code]; score >= minMatchScore && (!ok || score > m.Score) {
+			best[n.code] = StationMatch{Code: n.code, Name: n.display, Matched: n.name, Score: score}
+		}
+	}
+
+	matches := make([]StationMatch, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Code < matches[j].Code
+	})
+	return matches
+}
+
+// Lookup returns the station best matching query. It returns
+// ErrStationNotFound if nothing matches and an *AmbiguousStationError if
+// several stations match equally well.
+func (r *StationResolver) Lookup(query string) (StationMatch, error) {
+	matches := r.Resolve(query)
+	if len(matches) == 0 {
+		return StationMatch{}, ErrStationNotFound
+	}
+	n := 1
+	for n < len(matches) && matches[0].Score-matches[n].Score < ambiguityMargin {
+		n++
+	}
+	if n > 1 {
+		return StationMatch{}, &AmbiguousStationError{Query: query, Matches: matches[:n]}
+	}
+	return matches[0], nil
+}
+
+// matchTokens splits a name into lowercase words, expanding abbreviations
+// and dropping stop words.
+func matchTokens(s string) []string {
+	s = strings.ReplaceAll(strings.ToLower(s), "&", " & ")
+	s = strings.ReplaceAll(s, "'", "")
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&'
+	})
+	var tokens []string
+	for _, f := range fields {
+		expanded, ok := tokenSynonyms[f]
+		if !ok {
+			expanded = []string{f}
+		}
+		for _, t := range expanded {
+			if !stopTokens[t] {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	return tokens
+}
+
+// tokenScore rates how well the words of a query match the words of a
+// name. Every query word should match a word of the name, and matching
+// more of the name's words is better.
+func tokenScore(query, name []string) float64 {
+	total, matched := 0.0, map[int]bool{}
+	for _, q := range query {
+		best, bestIdx := 0.0, -1
+		for i, n := range name {
+			s := 0.0
+			switch {
+			case q == n:
+				s = 1
+			case len(q) >= 3 && strings.HasPrefix(n, q):
+				s = 0.9
+			default:
+				if s = similarity(q, n); s < minTokenSimilarity {
+					s = 0
+				}
+			}
+			if s > best {
+				best, bestIdx = s, i
+			}
+		}
+		total += best
+		if bestIdx >= 0 {
+			matched[bestIdx] = true
+		}
+	}
+	return 0.8*total/float64(len(query)) + 0.2*float64(len(matched))/float64(len(name))
+}
+
+// similarity is 1 minus the edit distance between a and b relative to the
+// longer of the two.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
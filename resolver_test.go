@@ -0,0 +1,113 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStationResolverLookup(t *testing.T) {
+	r := NewStationResolver(nil)
+	for _, q := range []struct {
+		query string
+		want  string
+	}{
+		{"NY Penn", "NY"},
+		{"new york", "NY"},
+		{"nY", "NY"},
+		{"newark airprt", "NA"},
+		{"Newark Penn", "NP"},
+		{"newark broad st", "ND"},
+		{"secaucus upper", "SE"},
+		{"Secaucus Lower Lvl", "TS"},
+		{"hobokn", "HB"},
+		{"hohokus", "UF"},
+		{"Mountain Av", "MS"},
+		{"montclair state university", "UV"},
+		{"trenton transit", "TR"},
+	} {
+		got, err := r.Lookup(q.query)
+		if err != nil || got.Code != q.want {
+			t.Errorf("Lookup(%q) got %+v, %v want %s", q.query, got, err, q.want)
+		}
+	}
+
+	for _, q := range []string{"", "  ", "xyzzy", "Baltimore"} {
+		if got, err := r.Lookup(q); !errors.Is(err, ErrStationNotFound) {
+			t.Errorf("Lookup(%q) got %+v, %v want ErrStationNotFound", q, got, err)
+		}
+	}
+}
+
+func TestStationResolverAmbiguous(t *testing.T) {
+	stations := []Station{
+		{ID: "SE", Name: "Secaucus", Aliases: []string{"Secaucus Upper Lvl"}},
+		{ID: "TS", Name: "Secaucus", Aliases: []string{"Secaucus Lower Lvl"}},
+		{ID: "HB", Name: "Hoboken"},
+	}
+	r := NewStationResolver(stations)
+
+	_, err := r.Lookup("secaucus")
+	var ambiguous *AmbiguousStationError
+	if !errors.As(err, &ambiguous) || !errors.Is(err, ErrAmbiguousStation) {
+		t.Fatalf("Lookup(secaucus) got %v want *AmbiguousStationError", err)
+	}
+	want := []StationMatch{
+		{Code: "SE", Name: "Secaucus Upper Lvl", Matched: "Secaucus", Score: 1},
+		{Code: "TS", Name: "Secaucus Lower Lvl", Matched: "Secaucus", Score: 1},
+	}
+	if diff := cmp.Diff(want, ambiguous.Matches); diff != "" {
+		t.Errorf("AmbiguousStationError.Matches mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := err.Error(), `"secaucus" could be Secaucus Upper Lvl (SE) or Secaucus Lower Lvl (TS)`; got != want {
+		t.Errorf("Error() got %q want %q", got, want)
+	}
+
+	if got, err := r.Lookup("secaucus lower"); err != nil || got.Code != "TS" {
+		t.Errorf("Lookup(secaucus lower) got %+v, %v want TS", got, err)
+	}
+	if got, err := r.Lookup("newark penn"); !errors.Is(err, ErrStationNotFound) {
+		t.Errorf("Lookup(newark penn) got %+v, %v want ErrStationNotFound for a station not in the list", got, err)
+	}
+
+	matches := r.Resolve("secaucus upper")
+	if len(matches) != 2 || matches[0].Code != "SE" || matches[1].Code != "TS" || matches[0].Score <= matches[1].Score {
+		t.Errorf("Resolve(secaucus upper) got %+v want SE ranked above TS", matches)
+	}
+}
+
+func TestClientStationResolver(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/getStationList.xml")
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	r, err := c.StationResolver(context.Background())
+	if err != nil {
+		t.Fatalf("StationResolver() unexpected error: %v", err)
+	}
+	if got, err := r.Lookup("woodclif lake"); err != nil || got.Code != "WL" {
+		t.Errorf("Lookup(woodclif lake) got %+v, %v want WL", got, err)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	for _, r := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"airport", "airprt", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	} {
+		if got := levenshtein([]rune(r.a), []rune(r.b)); got != r.want {
+			t.Errorf("levenshtein(%q, %q) got %d want %d", r.a, r.b, got, r.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how the client retries failed requests.
+//
+// The zero value of each field falls back to the value used by
+// DefaultRetryPolicy.
+type RetryPolicy struct {
+	MaxAttempts     int           // Total attempts, including the first one
+	InitialBackoff  time.Duration // Delay before the first retry
+	MaxBackoff      time.Duration // Upper bound on the delay between attempts, and on any Retry-After honored
+	Multiplier      float64       // Growth factor applied to the delay after each attempt
+	Jitter          float64       // Fraction (0-1) of each delay that is randomized; negative disables jitter
+	RetryableStatus []int         // HTTP status codes worth retrying
+
+	// RetryableError reports whether a transport error is worth retrying.
+	// If nil, timeouts and connection resets / refusals are retried.
+	RetryableError func(error) bool
+}
+
+// DefaultRetryPolicy returns a policy suited to the NJTransit API, which
+// tends to flap for a few seconds at a time during rush hour.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:     4,
+		InitialBackoff:  250 * time.Millisecond,
+		MaxBackoff:      5 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		RetryableStatus: []int{429, 500, 502, 503, 504},
+	}
+}
+
+// An Attempt records the outcome of a single HTTP request made while retrying.
+type Attempt struct {
+	Err        error         // Error returned by the attempt
+	StatusCode int           // HTTP status code, or 0 if no response was received
+	Duration   time.Duration // Time spent on the attempt
+}
+
+// RetryError is returned when a request fails under a RetryPolicy.
+// It unwraps to the error from the final attempt. If the context ends while
+// waiting to retry, the final attempt records the context's error so that
+// errors.Is(err, context.Canceled) holds just as it does without retries.
+type RetryError struct {
+	Attempts []Attempt // Every attempt made, in order
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("giving up after %d attempt(s): %v", len(e.Attempts), e.Unwrap())
+}
+
+func (e *RetryError) Unwrap() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+// cancelled records ctx's error as the final attempt, unless the last attempt
+// already failed because of it, and returns e.
+func (e *RetryError) cancelled(ctx context.Context, since time.Time) *RetryError {
+	if err := ctx.Err(); !errors.Is(e.Unwrap(), err) {
+		e.Attempts = append(e.Attempts, Attempt{Err: err, Duration: time.Since(since)})
+	}
+	return e
+}
+
+// randFloat is swapped out in tests to make jitter deterministic.
+var randFloat = rand.Float64
+
+// do calls fn until it succeeds, returns a non-retryable error, runs out of
+// attempts, or the next delay would not fit before the context deadline.
+func (p *RetryPolicy) do(ctx context.Context, fn func(context.Context) (*response, error)) (*response, error) {
+	d := DefaultRetryPolicy()
+	maxAttempts := orDefault(p.MaxAttempts, d.MaxAttempts)
+
+	rerr := &RetryError{}
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, err := fn(ctx)
+		if err == nil {
+			return resp, nil
+		}
+
+		a := Attempt{Err: err, Duration: time.Since(start)}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			a.StatusCode = apiErr.StatusCode
+		}
+		rerr.Attempts = append(rerr.Attempts, a)
+
+		if ctx.Err() != nil {
+			return nil, rerr.cancelled(ctx, time.Now())
+		}
+		if attempt >= maxAttempts || !p.retryable(err) {
+			return nil, rerr
+		}
+
+		wait := p.backoff(attempt)
+		if apiErr != nil && apiErr.retryAfter > 0 {
+			// Waiting longer than MaxBackoff would stall the caller on
+			// the server's say-so, so give up instead.
+			if apiErr.retryAfter > orDefault(p.MaxBackoff, d.MaxBackoff) {
+				return nil, rerr
+			}
+			wait = apiErr.retryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return nil, rerr
+		}
+
+		waitStart := time.Now()
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, rerr.cancelled(ctx, waitStart)
+		case <-t.C:
+		}
+	}
+}
+
+// backoff returns the delay to wait after the given (1-based) attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := DefaultRetryPolicy()
+	initial := orDefault(p.InitialBackoff, d.InitialBackoff)
+	maxBackoff := orDefault(p.MaxBackoff, d.MaxBackoff)
+	mult := orDefault(p.Multiplier, d.Multiplier)
+
+	wait := float64(initial) * math.Pow(mult, float64(attempt-1))
+	if wait > float64(maxBackoff) {
+		wait = float64(maxBackoff)
+	}
+	if jitter := orDefault(p.Jitter, d.Jitter); jitter > 0 {
+		wait -= wait * math.Min(jitter, 1) * randFloat()
+	}
+	return time.Duration(wait)
+}
+
+// retryable reports whether err is worth another attempt under this policy.
+//
+// Cancellation by the caller is handled by do, which checks the caller's
+// context, so a deadline seen here comes from a timeout on the attempt
+// itself, like http.Client.Timeout or WithRequestTimeout, and is retried.
+func (p *RetryPolicy) retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		codes := p.RetryableStatus
+		if codes == nil {
+			codes = DefaultRetryPolicy().RetryableStatus
+		}
+		for _, c := range codes {
+			if c == apiErr.StatusCode {
+				return true
+			}
+		}
+		return false
+	}
+
+	if p.RetryableError != nil {
+		return p.RetryableError(err)
+	}
+	return isTransientNetError(err)
+}
+
+// isTransientNetError reports whether err looks like a network blip.
+func isTransientNetError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date. It returns 0 if the header is missing or invalid.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func orDefault[T comparable](v, def T) T {
+	var zero T
+	if v == zero {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,274 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+}
+
+func TestRetrySucceedsAfterFailures(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		http.ServeFile(w, r, "testdata/getStationList.xml")
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetRetryPolicy(fastRetryPolicy())
+
+	if _, err := c.StationList(context.Background()); err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetRetryPolicy(fastRetryPolicy())
+
+	_, err := c.StationList(context.Background())
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *RetryError, got: %T %v", err, err)
+	}
+	if len(retryErr.Attempts) != 3 || calls != 3 {
+		t.Errorf("expected 3 attempts, got %d (%d calls)", len(retryErr.Attempts), calls)
+	}
+	for i, a := range retryErr.Attempts {
+		if a.StatusCode != http.StatusBadGateway {
+			t.Errorf("attempt %d status = %d, want %d", i, a.StatusCode, http.StatusBadGateway)
+		}
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("expected *APIError to be wrapped, got: %v", err)
+	}
+	if !errors.Is(err, ErrUnexpectedStatus) {
+		t.Errorf("expected ErrUnexpectedStatus, got: %v", err)
+	}
+}
+
+func TestRetryNonRetryableStatus(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetRetryPolicy(fastRetryPolicy())
+
+	if _, err := c.StationList(context.Background()); err == nil {
+		t.Fatal("expected error for 404 response, got nil")
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryAfterExceedsDeadline(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetRetryPolicy(fastRetryPolicy())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.StationList(ctx)
+	if err == nil {
+		t.Fatal("expected error for 429 response, got nil")
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected to give up immediately, took %v", elapsed)
+	}
+}
+
+func TestRetryAfterExceedsMaxBackoff(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, MaxBackoff: time.Second})
+
+	start := time.Now()
+	_, err := c.StationList(context.Background())
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *RetryError, got: %T %v", err, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected to give up immediately, took %v", elapsed)
+	}
+}
+
+func TestRetryCancelledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Cancel once the client is backing off from this response.
+		time.AfterFunc(50*time.Millisecond, cancel)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Minute})
+
+	_, err := c.StationList(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *RetryError, got: %T %v", err, err)
+	}
+	if n := len(retryErr.Attempts); n != 2 || retryErr.Attempts[0].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the 503 attempt followed by the cancellation, got %+v", retryErr.Attempts)
+	}
+}
+
+// stallFirst returns a handler which stalls the first request until the
+// test ends, and serves the station list to the others.
+func stallFirst(t *testing.T, calls *int32) http.HandlerFunc {
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	return func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+			return
+		}
+		http.ServeFile(w, r, "testdata/getStationList.xml")
+	}
+}
+
+func TestRetryHTTPClientTimeout(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(stallFirst(t, &calls))
+	defer ts.Close()
+
+	c := New(ts.URL, WithBasicAuth("username", "pa$$word"),
+		WithHTTPClient(&http.Client{Timeout: 100 * time.Millisecond}),
+		WithRetryPolicy(fastRetryPolicy()))
+
+	if _, err := c.StationList(context.Background()); err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the timed out attempt to be retried, got %d calls", calls)
+	}
+}
+
+func TestRetryRequestTimeout(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(stallFirst(t, &calls))
+	defer ts.Close()
+
+	c := New(ts.URL, WithBasicAuth("username", "pa$$word"),
+		WithRequestTimeout(100*time.Millisecond),
+		WithRetryPolicy(fastRetryPolicy()))
+
+	if _, err := c.StationList(context.Background()); err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the timed out attempt to be retried, got %d calls", calls)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	defer func(f func() float64) { randFloat = f }(randFloat)
+	randFloat = func() float64 { return 0.5 }
+
+	p := &RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     3,
+	}
+	for _, r := range []struct {
+		attempt int
+		jitter  float64
+		want    time.Duration
+	}{
+		{1, -1, 100 * time.Millisecond},
+		{2, -1, 300 * time.Millisecond},
+		{3, -1, 900 * time.Millisecond},
+		{4, -1, time.Second},
+		{1, 0, 90 * time.Millisecond},
+		{1, 0.5, 75 * time.Millisecond},
+		{4, 0.2, 900 * time.Millisecond},
+	} {
+		p.Jitter = r.jitter
+		if got := p.backoff(r.attempt); got != r.want {
+			t.Errorf("backoff(%d) with jitter %v got %v want %v", r.attempt, r.jitter, got, r.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+	for _, r := range []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"Fri, 03 May 2024 12:00:30 GMT", 30 * time.Second},
+		{"Fri, 03 May 2024 11:00:00 GMT", 0},
+		{"soon", 0},
+	} {
+		if got := parseRetryAfter(r.header, now); got != r.want {
+			t.Errorf("parseRetryAfter(%q) got %v want %v", r.header, got, r.want)
+		}
+	}
+}
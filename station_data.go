@@ -2,8 +2,6 @@ package njtapi
 
 import (
 	"context"
-	"encoding/xml"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -15,108 +13,110 @@ const (
 
 // A Station provides information about the next trains stopping at a station.
 type Station struct {
-	ID         string         // Station character code
-	Name       string         // Station name
-	Aliases    []string       // Additional names for this station
-	Departures []StationTrain // Trains departing from this station
+	ID         string          // Station character code
+	Name       string          // Station name
+	Aliases    []string        // Additional names for this station
+	Banners    []BannerMessage // Station-wide service advisories
+	Departures []StationTrain  // Trains departing from this station
+	Stale      bool            // Served from the cache because the API could not be reached
 }
 
 // A StationTrain models a train which is scheduled to depart from a station.
 type StationTrain struct {
-	Index                  int           // Row index
-	TrainID                int           // Train ID
-	Line                   string        // Train line
-	LineAbbrv              string        // Train line abbreviation
-	Destination            string        // Destination for the train
-	ScheduledDepartureDate time.Time     // Scheduled departure time from the station
-	Track                  string        // Track number/letter
-	Status                 string        // Current train status
-	SecondsLate            time.Duration // Train delay
-	LatLng                 *LatLng       // Train location
-	LatLngTimestamp        time.Time     // Time the train location was measured
-	InlineMsg              string        // In-line message for the train at this station
-	Stops                  []StationStop // List of all stops for this train.
-	ParseErrors            []error       // Errors encountered while parsing this train
+	Index                  int              // Row index
+	TrainID                TrainID          // Train identifier, including Amtrak trains
+	Line                   Line             // Train line, or zero if not sent
+	Destination            string           // Destination for the train, cleaned of markup and service markers
+	DestinationFlags       DestinationFlags // Service markers found in the destination
+	RawDestination         string           // Destination as sent by the API
+	ScheduledDepartureDate time.Time        // Scheduled departure time from the station
+	Track                  string           // Track number/letter
+	Status                 TrainStatus      // Current train status
+	SecondsLate            time.Duration    // Train delay
+	LatLng                 *LatLng          // Train location
+	LatLngTimestamp        time.Time        // Time the train location was measured
+	InlineMsg              string           // In-line message for the train at this station
+	ConnectingTrainID      *TrainID         // Train to connect to, if passengers must transfer
+	LastModified           time.Time        // Time this departure was last updated
+	BackColor              *Color           // Background color of the line on departure boards
+	ForeColor              *Color           // Text color of the line on departure boards
+	ShadowColor            *Color           // Text shadow color of the line on departure boards
+	StationPosition        int              // Position of this station along the train's route, 0 at its origin
+	Stops                  []StationStop    // List of all stops for this train.
+	ParseErrors            []error          // Errors encountered while parsing this train
 }
 
 // A StationStop is a stop this train will make, or has made, on it's route.
 type StationStop struct {
-	Name          string    // Station stop name
-	Time          time.Time // Actual (if already left) or planned (if upcoming) departure time from this stop
-	Departed      bool      // Indicates if the train has departed the stop or not
-	DepartureTime time.Time // Time the train was intially scheduled to depart this station
-	Lines         []Line    // Connecting lines available at this station
-	Status        string    // Current status of the train at this stop
-	ParseErrors   []error   // Errors encountered while parsing this stop
-}
-
-// A Line is train line, like the North Jersey Coast Line.
-type Line struct {
-	Name string // Train line
+	Name            string          // Station stop name
+	StationID       string          // Station character code, if known
+	StationIDSource StationIDSource // How StationID was determined
+	Time            time.Time       // Actual (if already left) or planned (if upcoming) departure time from this stop
+	Departed        bool            // Indicates if the train has departed the stop or not
+	DepartureTime   time.Time       // Time the train was intially scheduled to depart this station
+	Lines           []Line          // Connecting lines available at this station
+	Status          string          // Current status of the train at this stop
+	ParseErrors     []error         // Errors encountered while parsing this stop
 }
 
 // StationData returns details about upcoming trains stopping at a station.
 func (c *Client) StationData(ctx context.Context, station string) (*Station, error) {
-	resp, err := c.fetch(ctx, stationDataEndpoint, map[string]string{"station": station})
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		XMLName      xml.Name `xml:"STATION"`
-		Station2Char string   `xml:"STATION_2CHAR"`
-		StationName  string   `xml:"STATIONNAME"`
-		Items        []struct {
-			Index                  int    `xml:"ITEM_INDEX"`
-			ScheduledDepartureDate string `xml:"SCHED_DEP_DATE"`
-			Destination            string `xml:"DESTINATION"`
-			Track                  string `xml:"TRACK"`
-			Line                   string `xml:"LINE"`
-			TrainID                string `xml:"TRAIN_ID"`
-			ConnectingTrainID      string `xml:"CONNECTING_TRAIN_ID"`
-			Status                 string `xml:"STATUS"`
-			SecondsLate            int    `xml:"SEC_LATE"`
-			LastModified           string `xml:"LAST_MODIFIED"`
-			BackColor              string `xml:"BACKCOLOR"`
-			ForeColor              string `xml:"FORECOLOR"`
-			ShadowColor            string `xml:"SHADOWCOLOR"`
-			GPSTime                string `xml:"GPSTIME"`
-			LineAbbreviation       string `xml:"LINEABBREVIATION"`
-			InlineMsg              string `xml:"INLINEMSG"`
-			Longitude              string `xml:"GPSLONGITUDE"`
-			Latitude               string `xml:"GPSLATITUDE"`
-			Stops                  []struct {
-				Name     string `xml:"NAME"`
-				Time     string `xml:"TIME"`
-				Departed string `xml:"DEPARTED"`
-			} `xml:"STOPS>STOP"`
-		} `xml:"ITEMS>ITEM"`
-	}{}
+	params := map[string]string{"station": station}
+	info := CallInfo{Method: "StationData", Endpoint: stationDataEndpoint, Station: station}
+	return invoke(ctx, c, info, params, func(ctx context.Context) (*Station, error) {
+		return c.stationData(ctx, params)
+	})
+}
 
-	err = xml.Unmarshal(resp, &data)
+func (c *Client) stationData(ctx context.Context, params map[string]string) (*Station, error) {
+	var data stationDataWire
+	resp, err := c.load(ctx, stationDataEndpoint, params, &data)
 	if err != nil {
 		return nil, err
 	}
 
 	trains := []StationTrain{}
 	for _, r := range data.Items {
-		tID, err := strconv.Atoi(r.TrainID)
+		tID := ParseTrainID(r.TrainID)
+		train := StationTrain{
+			Index:          int(r.Index),
+			RawDestination: r.Destination,
+			Track:          strings.TrimSpace(r.Track),
+			TrainID:        tID,
+			Status:         ParseTrainStatus(r.Status),
+			SecondsLate:    time.Duration(r.SecondsLate) * time.Second,
+			InlineMsg:      strings.TrimSpace(r.InlineMsg),
+
+			StationPosition: int(r.StationPosition),
+		}
+		train.Destination, train.DestinationFlags = normalizeDestination(r.Destination)
+		if id := strings.TrimSpace(r.ConnectingTrainID); id != "" {
+			connecting := ParseTrainID(id)
+			train.ConnectingTrainID = &connecting
+		}
+		train.LastModified, err = c.parseTime(r.LastModified)
 		if err != nil {
-			// Skip trains that don't have a numeric ID.
-			// These are Amtrak trains with "A123" style IDs.
-			continue
+			train.ParseErrors = append(train.ParseErrors, &ParseError{
+				Field: "LAST_MODIFIED", Value: r.LastModified, Err: err,
+			})
 		}
-		train := StationTrain{
-			Index:       r.Index,
-			Destination: r.Destination,
-			Track:       strings.TrimSpace(r.Track),
-			Line:        r.Line,
-			TrainID:     tID,
-			Status:      strings.TrimSpace(r.Status),
-			SecondsLate: time.Duration(r.SecondsLate) * time.Second,
-			LineAbbrv:   r.LineAbbreviation,
-			InlineMsg:   strings.TrimSpace(r.InlineMsg),
+		for _, f := range []struct {
+			field string
+			value string
+			dst   **Color
+		}{
+			{"BACKCOLOR", r.BackColor, &train.BackColor},
+			{"FORECOLOR", r.ForeColor, &train.ForeColor},
+			{"SHADOWCOLOR", r.ShadowColor, &train.ShadowColor},
+		} {
+			*f.dst, err = parseColor(f.value)
+			if err != nil {
+				train.ParseErrors = append(train.ParseErrors, &ParseError{
+					Field: f.field, Value: f.value, Err: err,
+				})
+			}
 		}
+		train.Line = resolveLine(r.LineAbbreviation, r.Line)
 		train.ScheduledDepartureDate, err = c.parseTime(r.ScheduledDepartureDate)
 		if err != nil {
 			train.ParseErrors = append(train.ParseErrors, &ParseError{
@@ -138,7 +138,8 @@ func (c *Client) StationData(ctx context.Context, station string) (*Station, err
 
 		stops := make([]StationStop, len(r.Stops))
 		for j, s := range r.Stops {
-			stops[j] = StationStop{Name: strings.TrimSpace(s.Name)}
+			stops[j] = StationStop{Name: strings.TrimSpace(s.Name), Status: strings.TrimSpace(s.Status)}
+			resolveStationID(&stops[j], "")
 			stops[j].Time, err = c.parseTime(s.Time)
 			if err != nil {
 				stops[j].ParseErrors = append(stops[j].ParseErrors, &ParseError{
@@ -146,43 +147,60 @@ func (c *Client) StationData(ctx context.Context, station string) (*Station, err
 				})
 			}
 			stops[j].Departed = (s.Departed == "YES")
+			c.logParseErrors(ctx, stationDataEndpoint, r.TrainID, stops[j].ParseErrors)
 		}
 		train.Stops = stops
+		c.logParseErrors(ctx, stationDataEndpoint, r.TrainID, train.ParseErrors)
 		trains = append(trains, train)
 	}
 
-	s := &Station{ID: data.Station2Char, Name: data.StationName, Departures: trains}
+	s := &Station{
+		ID:         data.Station2Char,
+		Name:       data.StationName,
+		Banners:    c.parseBanners(ctx, data.Banners),
+		Departures: trains,
+		Stale:      resp.stale,
+	}
 	return s, nil
 }
 
 // StationList returns a list of all the stations available.
 func (c *Client) StationList(ctx context.Context) ([]Station, error) {
-	resp, err := c.fetch(ctx, stationListEndpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		XMLName xml.Name `xml:"STATIONS"`
-		Station []struct {
-			Name         string `xml:"STATIONNAME"`
-			Station2Char string `xml:"STATION_2CHAR"`
-		} `xml:"STATION"`
-	}{}
+	info := CallInfo{Method: "StationList", Endpoint: stationListEndpoint}
+	return invoke(ctx, c, info, nil, c.stationList)
+}
 
-	err = xml.Unmarshal(resp, &data)
+func (c *Client) stationList(ctx context.Context) ([]Station, error) {
+	var data stationListWire
+	resp, err := c.load(ctx, stationListEndpoint, nil, &data)
 	if err != nil {
 		return nil, err
 	}
 
 	stations := []Station{}
-	for _, r := range data.Station {
+	for _, r := range data.Stations {
 		stations = append(stations, Station{
 			Name:    strings.TrimSpace(r.Name),
 			ID:      r.Station2Char,
-			Aliases: extraStations[r.Station2Char],
+			Aliases: stationAliases(r.Station2Char, r.Name),
+			Stale:   resp.stale,
 		})
 	}
 	return stations, nil
 }
 
+// stationAliases returns the names the station dataset knows for a station,
+// other than the one the API sent.
+func stationAliases(code, name string) []string {
+	info, ok := stations.byCode[code]
+	if !ok {
+		return nil
+	}
+	var aliases []string
+	for _, n := range append([]string{info.Name}, info.Aliases...) {
+		if normalizeName(n) != normalizeName(name) {
+			aliases = append(aliases, n)
+		}
+	}
+	return aliases
+}
@@ -42,10 +42,10 @@ func TestStationList(t *testing.T) {
 	}
 
 	want := []Station{
-		{ID: "NY", Name: "New York", Aliases: []string{"New York Penn Station"}},
+		{ID: "NY", Name: "New York", Aliases: []string{"New York Penn Station", "New York Penn"}},
 		{ID: "NP", Name: "Newark Penn", Aliases: []string{"Newark Penn Station"}},
-		{ID: "SE", Name: "Secaucus", Aliases: []string{"Secaucus Upper Lvl"}},
-		{ID: "TS", Name: "Secaucus", Aliases: []string{"Secaucus Lower Lvl"}},
+		{ID: "SE", Name: "Secaucus", Aliases: []string{"Secaucus Upper Lvl", "Secaucus Junction Upper Level"}},
+		{ID: "TS", Name: "Secaucus", Aliases: []string{"Secaucus Lower Lvl", "Secaucus Junction Lower Level"}},
 		{ID: "WL", Name: "Woodcliff Lake"},
 		{ID: "SC", Name: ""},
 	}
@@ -94,6 +94,10 @@ func TestStationData(t *testing.T) {
 
 	c := NewClient(ts.URL, "username", "pa$$word")
 
+	connecting := NJTTrainID(4383)
+	red, white, black := &Color{0xff, 0, 0}, &Color{0xff, 0xff, 0xff}, &Color{0, 0, 0}
+	cornflowerBlue := &Color{0x64, 0x95, 0xed}
+
 	for _, r := range []struct {
 		station string
 		want    *Station
@@ -106,48 +110,61 @@ func TestStationData(t *testing.T) {
 				Departures: []StationTrain{
 					{
 						Index:                  0,
-						TrainID:                3883,
-						Line:                   "Northeast Corridor Line",
-						LineAbbrv:              "NEC",
-						Destination:            "Trenton &#9992",
+						TrainID:                NJTTrainID(3883),
+						Line:                   lineNortheastCorridor,
+						Destination:            "Trenton",
+						DestinationFlags:       DestinationAirport,
+						RawDestination:         "Trenton &#9992",
 						ScheduledDepartureDate: time.Date(2019, 11, 18, 20, 17, 0, 0, loc),
 						Track:                  "B",
-						Status:                 "in 4 Min",
+						Status:                 TrainStatus{Kind: StatusOnTime, Minutes: 4, Text: "in 4 Min"},
 						SecondsLate:            4 * time.Minute,
 						LatLng:                 &LatLng{Lat: 40.7706, Lng: -74.0403},
 						LatLngTimestamp:        time.Date(2019, 11, 18, 20, 16, 45, 0, loc),
 						InlineMsg:              "",
+						LastModified:           time.Date(2019, 11, 18, 20, 16, 45, 0, loc),
+						BackColor:              red,
+						ForeColor:              white,
+						ShadowColor:            black,
+						StationPosition:        1,
 						Stops: []StationStop{
-							{Name: "New York Penn Station", Time: time.Date(2019, 11, 18, 20, 7, 0, 0, loc), Departed: true},
-							{Name: "Secaucus Upper Lvl", Time: time.Date(2019, 11, 18, 20, 20, 30, 0, loc), Departed: false},
-							{Name: "Newark Penn Station", Time: time.Date(2019, 11, 18, 20, 28, 45, 0, loc), Departed: false},
-							{Name: "Newark Airport", Time: time.Date(2019, 11, 18, 20, 35, 0, 0, loc), Departed: false},
-							{Name: "North Elizabeth", Time: time.Date(2019, 11, 18, 20, 38, 45, 0, loc), Departed: false},
-							{Name: "Elizabeth", Time: time.Date(2019, 11, 18, 20, 41, 30, 0, loc), Departed: false},
-							{Name: "Linden", Time: time.Date(2019, 11, 18, 20, 46, 45, 0, loc), Departed: false},
-							{Name: "Rahway", Time: time.Date(2019, 11, 18, 20, 51, 00, 0, loc), Departed: false},
-							{Name: "Metropark", Time: time.Date(2019, 11, 18, 20, 59, 45, 0, loc), Departed: false},
-							{Name: "Metuchen", Time: time.Date(2019, 11, 18, 21, 04, 15, 0, loc), Departed: false},
-							{Name: "Edison", Time: time.Date(2019, 11, 18, 21, 9, 15, 0, loc), Departed: false},
-							{Name: "New Brunswick", Time: time.Date(2019, 11, 18, 21, 13, 30, 0, loc), Departed: false},
-							{Name: "Jersey Avenue", Time: time.Date(2019, 11, 18, 21, 18, 15, 0, loc), Departed: false},
-							{Name: "Princeton Junction", Time: time.Date(2019, 11, 18, 21, 30, 45, 0, loc), Departed: false},
-							{Name: "Hamilton", Time: time.Date(2019, 11, 18, 21, 37, 15, 0, loc), Departed: false},
-							{Name: "Trenton", Time: time.Date(2019, 11, 18, 21, 50, 15, 0, loc), Departed: false},
+							{Name: "New York Penn Station", StationID: "NY", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 7, 0, 0, loc), Departed: true, Status: "BOARDING"},
+							{Name: "Secaucus Upper Lvl", StationID: "SE", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 20, 30, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Newark Penn Station", StationID: "NP", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 28, 45, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Newark Airport", StationID: "NA", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 35, 0, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "North Elizabeth", StationID: "NZ", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 38, 45, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Elizabeth", StationID: "EZ", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 41, 30, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Linden", StationID: "LI", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 46, 45, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Rahway", StationID: "RH", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 51, 00, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Metropark", StationID: "MP", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 59, 45, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Metuchen", StationID: "MU", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 04, 15, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Edison", StationID: "ED", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 9, 15, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "New Brunswick", StationID: "NB", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 13, 30, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Jersey Avenue", StationID: "JA", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 18, 15, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Princeton Junction", StationID: "PJ", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 30, 45, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Hamilton", StationID: "HL", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 37, 15, 0, loc), Departed: false, Status: "OnTime"},
+							{Name: "Trenton", StationID: "TR", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 50, 15, 0, loc), Departed: false, Status: "OnTime"},
 						},
 					}, {
 						Index:                  1,
-						TrainID:                3283,
-						Line:                   "North Jersey Coast Line",
-						LineAbbrv:              "NJCL",
-						Destination:            "Long Branch-BH &#9992",
+						TrainID:                NJTTrainID(3283),
+						Line:                   lineNorthJerseyCoast,
+						Destination:            "Long Branch-BH",
+						DestinationFlags:       DestinationAirport,
+						RawDestination:         "Long Branch-BH &#9992",
 						ScheduledDepartureDate: time.Date(2019, 11, 18, 20, 31, 30, 0, loc),
 						Track:                  "B",
 						LatLngTimestamp:        time.Date(2019, 11, 18, 20, 05, 33, 0, loc),
 						InlineMsg:              "",
+						ConnectingTrainID:      &connecting,
+						LastModified:           time.Date(2019, 11, 18, 20, 5, 35, 0, loc),
+						BackColor:              cornflowerBlue,
+						ForeColor:              white,
+						ShadowColor:            black,
+						StationPosition:        1,
 						Stops: []StationStop{
-							{Name: "New York Penn Station", Time: time.Date(2019, 11, 18, 20, 22, 0, 0, loc), Departed: false},
-							{Name: "Secaucus Upper Lvl", Time: time.Date(2019, 11, 18, 20, 31, 0, 0, loc), Departed: false},
+							{Name: "New York Penn Station", StationID: "NY", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 22, 0, 0, loc), Departed: false, Status: "BOARDING"},
+							{Name: "Secaucus Upper Lvl", StationID: "SE", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 31, 0, 0, loc), Departed: false},
 						},
 					},
 				},
@@ -158,22 +175,56 @@ func TestStationData(t *testing.T) {
 				ID:   "NY",
 				Name: "New York",
 				Departures: []StationTrain{
-					// The firsr train, index 0, is skipped because it's an Amtrak train.
 					{
+						Index:                  0,
+						TrainID:                TrainID{Raw: "A137", Number: 137, Operator: OperatorAmtrak},
+						Line:                   Line{Name: "REGIONAL"},
+						Destination:            "Washington",
+						DestinationFlags:       DestinationAirport,
+						RawDestination:         "Washington &#9992",
+						ScheduledDepartureDate: time.Date(2019, 11, 18, 18, 25, 0, 0, loc),
+						Status:                 TrainStatus{Kind: StatusUnknown, Text: "STAND BY"},
+						SecondsLate:            8700 * time.Second,
+						LatLng:                 &LatLng{Lat: 40.7455, Lng: -73.9819},
+						LatLngTimestamp:        time.Date(2019, 11, 18, 20, 17, 44, 0, loc),
+						LastModified:           time.Date(2019, 11, 18, 20, 17, 46, 0, loc),
+						BackColor:              &Color{0xff, 0xff, 0x00},
+						ForeColor:              black,
+						ShadowColor:            &Color{0xff, 0xff, 0x00},
+						Stops: []StationStop{
+							{Name: "New York", StationID: "NY", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 50, 0, 0, loc), Departed: false, Status: "STAND BY"},
+							{Name: "Newark Penn", StationID: "NP", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 7, 0, 0, loc), Departed: false, Status: "2HR 15M LATE"},
+							{Name: "Newark Airport", StationID: "NA", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 12, 0, 0, loc), Departed: false, Status: "2HR 15M LATE"},
+							{Name: "Metropark", StationID: "MP", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 26, 0, 0, loc), Departed: false, Status: "2HR 15M LATE"},
+							{Name: "Trenton", StationID: "TR", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 21, 24, 0, 0, loc), Departed: false, Status: "2 HOURS LATE"},
+							{Name: "Philadelphia", StationID: "PH", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 22, 21, 0, 0, loc), Departed: false, Status: "Delayed"},
+							{Name: "Wilmington", Time: time.Date(2019, 11, 18, 21, 55, 0, 0, loc), Departed: false, Status: "Late"},
+							{Name: "Baltimore", Time: time.Date(2019, 11, 18, 22, 5, 0, 0, loc), Departed: false, Status: "Late"},
+							{Name: "BWI Airport", Time: time.Date(2019, 11, 18, 22, 5, 0, 0, loc), Departed: false, Status: "Late"},
+							{Name: "New Carrollton", Time: time.Date(2019, 11, 18, 22, 5, 0, 0, loc), Departed: false, Status: "Late"},
+							{Name: "Washington", Time: time.Date(2019, 11, 18, 23, 31, 0, 0, loc), Departed: false, Status: "Late"},
+						},
+					}, {
 						Index:                  1,
-						TrainID:                3283,
-						Line:                   "North Jersey Coast Line",
-						LineAbbrv:              "NJCL",
-						Destination:            "Long Branch-BH -SEC &#9992",
+						TrainID:                NJTTrainID(3283),
+						Line:                   lineNorthJerseyCoast,
+						Destination:            "Long Branch-BH",
+						DestinationFlags:       DestinationAirport | DestinationViaSecaucus,
+						RawDestination:         "Long Branch-BH -SEC &#9992",
 						ScheduledDepartureDate: time.Date(2019, 11, 18, 20, 22, 0, 0, loc),
 						Track:                  "7",
-						Status:                 "BOARDING",
+						Status:                 TrainStatus{Kind: StatusBoarding, Text: "BOARDING"},
 						SecondsLate:            -1 * time.Minute,
 						LatLngTimestamp:        time.Date(2019, 11, 18, 20, 05, 33, 0, loc),
 						InlineMsg:              "",
+						ConnectingTrainID:      &connecting,
+						LastModified:           time.Date(2019, 11, 18, 20, 11, 44, 0, loc),
+						BackColor:              cornflowerBlue,
+						ForeColor:              white,
+						ShadowColor:            black,
 						Stops: []StationStop{
-							{Name: "New York Penn Station", Time: time.Date(2019, 11, 18, 20, 22, 0, 0, loc), Departed: false},
-							{Name: "Secaucus Upper Lvl", Time: time.Date(2019, 11, 18, 20, 31, 0, 0, loc), Departed: false},
+							{Name: "New York Penn Station", StationID: "NY", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 22, 0, 0, loc), Departed: false, Status: "BOARDING"},
+							{Name: "Secaucus Upper Lvl", StationID: "SE", StationIDSource: StationIDFromRegistry, Time: time.Date(2019, 11, 18, 20, 31, 0, 0, loc), Departed: false},
 						},
 					},
 				},
@@ -262,3 +313,103 @@ func TestStationDataParseErrors(t *testing.T) {
 		t.Errorf("expected *ParseError for stop, got %T", train.Stops[0].ParseErrors[0])
 	}
 }
+
+func TestStationDataDisplayFieldErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<STATION><ITEMS><ITEM>
+<TRAIN_ID>1234</TRAIN_ID>
+<SCHED_DEP_DATE>18-Nov-2019 08:00:00 PM</SCHED_DEP_DATE>
+<GPSTIME>18-Nov-2019 08:00:00 PM</GPSTIME>
+<CONNECTING_TRAIN_ID>A12</CONNECTING_TRAIN_ID>
+<LAST_MODIFIED>yesterday</LAST_MODIFIED>
+<BACKCOLOR>blurple</BACKCOLOR>
+<FORECOLOR>#FFF</FORECOLOR>
+</ITEM></ITEMS></STATION>`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	station, err := c.StationData(context.Background(), "SE")
+	if err != nil {
+		t.Fatalf("StationData() error: %v", err)
+	}
+	train := station.Departures[0]
+
+	var fields []string
+	for _, e := range train.ParseErrors {
+		var pe *ParseError
+		if errors.As(e, &pe) {
+			fields = append(fields, pe.Field)
+		}
+	}
+	if diff := cmp.Diff([]string{"LAST_MODIFIED", "BACKCOLOR"}, fields); diff != "" {
+		t.Errorf("ParseErrors fields mismatch (-want +got):\n%s", diff)
+	}
+	if train.BackColor != nil || train.ShadowColor != nil {
+		t.Errorf("unparseable fields should be nil, got %+v", train)
+	}
+	// Amtrak connections are valid train IDs.
+	if want := (&TrainID{Raw: "A12", Number: 12, Operator: OperatorAmtrak}); !cmp.Equal(want, train.ConnectingTrainID) {
+		t.Errorf("ConnectingTrainID got %+v want %+v", train.ConnectingTrainID, want)
+	}
+	if want := (&Color{0xff, 0xff, 0xff}); !cmp.Equal(want, train.ForeColor) {
+		t.Errorf("ForeColor got %v want %v", train.ForeColor, want)
+	}
+}
+
+func TestStationDataUnrecognizedID(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<STATION><STATION_2CHAR>NY</STATION_2CHAR><ITEMS><ITEM><TRAIN_ID>TBD</TRAIN_ID><DESTINATION>Trenton</DESTINATION></ITEM></ITEMS></STATION>`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	station, err := c.StationData(context.Background(), "NY")
+	if err != nil {
+		t.Fatalf("StationData() error: %v", err)
+	}
+	if len(station.Departures) != 1 || station.Departures[0].TrainID != (TrainID{Raw: "TBD", Operator: OperatorOther}) {
+		t.Errorf("StationData() got %+v want the TBD train", station.Departures)
+	}
+}
+
+func TestStationDataBanners(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Error loading timezones: %v", err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/getTrainSchedule3.xml")
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	station, err := c.StationData(context.Background(), "HB")
+	if err != nil {
+		t.Fatalf("StationData() unexpected error: %v", err)
+	}
+
+	want := []BannerMessage{
+		{
+			ID:       "1042",
+			Text:     "Hoboken-bound trains are subject to 15 minute delays due to Amtrak overhead wire problems near Newark.",
+			Type:     "alert",
+			Severity: SeverityAlert,
+			Start:    time.Date(2024, 7, 23, 18, 45, 0, 0, loc),
+			End:      time.Date(2024, 7, 23, 22, 0, 0, 0, loc),
+		}, {
+			ID:       "1043",
+			Text:     "Weekend track work on the Morristown Line. Check the schedule before you travel.",
+			Type:     "banner",
+			Severity: SeverityInfo,
+			Start:    time.Date(2024, 7, 22, 9, 0, 0, 0, loc),
+		},
+	}
+	if diff := cmp.Diff(want, station.Banners); diff != "" {
+		t.Errorf("StationData() banners mismatch (-want +got):\n%s", diff)
+	}
+	if len(station.Departures) != 1 {
+		t.Errorf("StationData() got %d departures want 1", len(station.Departures))
+	}
+}
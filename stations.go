@@ -0,0 +1,190 @@
+package njtapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// stationsJSON is the station dataset. Bump its version whenever stations,
+// names or metadata change. Accessibility comes from NJ Transit's rail GTFS
+// feed via data/import_gtfs.go, and the dataset's note lists the stations
+// it has not covered yet.
+//
+//go:embed data/stations.json
+var stationsJSON []byte
+
+// Accessibility records whether a station is accessible to people with
+// disabilities.
+type Accessibility int
+
+// Station accessibility values.
+const (
+	AccessibilityUnknown Accessibility = iota // Not recorded in the dataset
+	Accessible                                // Accessible station
+	NotAccessible                             // Station is not accessible
+)
+
+func (a Accessibility) String() string {
+	switch a {
+	case Accessible:
+		return "accessible"
+	case NotAccessible:
+		return "not accessible"
+	}
+	return "unknown"
+}
+
+// StationInfo is static information about a station from the dataset
+// embedded in this package, which needs no API calls.
+type StationInfo struct {
+	Code          string        // Station character code, like "NY"
+	Name          string        // Canonical station name
+	Aliases       []string      // Other names used by the API for this station
+	LatLng        LatLng        // Station location
+	Lines         []Line        // Lines serving the station
+	Accessibility Accessibility // Whether the station is accessible, if recorded
+}
+
+// clone returns a copy of s which shares no memory with the dataset.
+func (s *StationInfo) clone() StationInfo {
+	c := *s
+	c.Aliases = append([]string(nil), s.Aliases...)
+	c.Lines = append([]Line(nil), s.Lines...)
+	return c
+}
+
+// stationDataset is the decoded form of stationsJSON.
+type stationDataset struct {
+	Version  string `json:"version"`
+	Stations []struct {
+		Code       string   `json:"code"`
+		Name       string   `json:"name"`
+		Aliases    []string `json:"aliases"`
+		Lat        float64  `json:"lat"`
+		Lng        float64  `json:"lng"`
+		Lines      []string `json:"lines"`
+		Accessible *bool    `json:"accessible"`
+	} `json:"stations"`
+}
+
+// stationRegistry is the station dataset, indexed for lookups.
+type stationRegistry struct {
+	version  string
+	stations []*StationInfo
+	byCode   map[string]*StationInfo
+	byName   map[string]*StationInfo
+}
+
+var stations = mustLoadStations(stationsJSON)
+
+// loadStations decodes and indexes a station dataset.
+func loadStations(b []byte) (*stationRegistry, error) {
+	var data stationDataset
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	r := &stationRegistry{
+		version: data.Version,
+		byCode:  map[string]*StationInfo{},
+		byName:  map[string]*StationInfo{},
+	}
+	for _, s := range data.Stations {
+		info := &StationInfo{
+			Code:    s.Code,
+			Name:    s.Name,
+			Aliases: s.Aliases,
+			LatLng:  LatLng{Lat: s.Lat, Lng: s.Lng},
+		}
+		if s.Accessible != nil {
+			info.Accessibility = NotAccessible
+			if *s.Accessible {
+				info.Accessibility = Accessible
+			}
+		}
+		for _, code := range s.Lines {
+			l, ok := LookupLine(code)
+			if !ok {
+				return nil, fmt.Errorf("station %s: unknown line %q", s.Code, code)
+			}
+			info.Lines = append(info.Lines, l)
+		}
+		if _, ok := r.byCode[s.Code]; ok {
+			return nil, fmt.Errorf("duplicate station code %q", s.Code)
+		}
+		r.byCode[s.Code] = info
+		for _, n := range append([]string{s.Name}, s.Aliases...) {
+			key := normalizeName(n)
+			if other, ok := r.byName[key]; ok {
+				return nil, fmt.Errorf("name %q is used by both %s and %s", n, other.Code, s.Code)
+			}
+			r.byName[key] = info
+		}
+		r.stations = append(r.stations, info)
+	}
+	sort.Slice(r.stations, func(i, j int) bool { return r.stations[i].Code < r.stations[j].Code })
+	return r, nil
+}
+
+func mustLoadStations(b []byte) *stationRegistry {
+	r, err := loadStations(b)
+	if err != nil {
+		panic("njtapi: loading station dataset: " + err.Error())
+	}
+	return r
+}
+
+// StationDataVersion returns the version of the embedded station dataset.
+func StationDataVersion() string {
+	return stations.version
+}
+
+// Stations returns every station in the embedded dataset, ordered by code.
+// The results are copies, so changing them does not affect the dataset.
+func Stations() []StationInfo {
+	all := make([]StationInfo, len(stations.stations))
+	for i, info := range stations.stations {
+		all[i] = info.clone()
+	}
+	return all
+}
+
+// LookupStation finds a station in the embedded dataset by its code, name
+// or one of its aliases, ignoring case and extra whitespace.
+func LookupStation(s string) (StationInfo, bool) {
+	info, ok := stations.byCode[strings.ToUpper(strings.TrimSpace(s))]
+	if !ok {
+		info, ok = stations.byName[normalizeName(s)]
+	}
+	if !ok {
+		return StationInfo{}, false
+	}
+	return info.clone(), true
+}
+
+// NearestStations returns up to n stations from the embedded dataset,
+// closest to p first.
+func NearestStations(p LatLng, n int) []StationInfo {
+	all := Stations()
+	sort.SliceStable(all, func(i, j int) bool {
+		return p.Distance(all[i].LatLng) < p.Distance(all[j].LatLng)
+	})
+	if n = max(n, 0); n < len(all) {
+		all = all[:n]
+	}
+	return all
+}
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// Distance returns the great-circle distance between two points in meters.
+func (l LatLng) Distance(o LatLng) float64 {
+	lat1, lat2 := l.Lat*math.Pi/180, o.Lat*math.Pi/180
+	dLat, dLng := lat2-lat1, (o.Lng-l.Lng)*math.Pi/180
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
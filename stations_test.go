@@ -0,0 +1,140 @@
+package njtapi
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStationDataset(t *testing.T) {
+	if StationDataVersion() == "" {
+		t.Error("StationDataVersion() is empty")
+	}
+	all := Stations()
+	if len(all) < 100 {
+		t.Errorf("Stations() returned only %d stations", len(all))
+	}
+	for _, s := range all {
+		if s.LatLng.Lat < 39 || s.LatLng.Lat > 42 || s.LatLng.Lng < -76 || s.LatLng.Lng > -73 {
+			t.Errorf("%s is outside the service area: %v", s.Code, s.LatLng)
+		}
+		if len(s.Lines) == 0 {
+			t.Errorf("%s is not served by any line", s.Code)
+		}
+	}
+}
+
+func TestLoadStationsErrors(t *testing.T) {
+	for _, r := range []struct {
+		data string
+		want string
+	}{
+		{`{`, "unexpected end"},
+		{`{"stations": [{"code": "NY", "name": "New York", "lines": ["XX"]}]}`, "unknown line"},
+		{`{"stations": [{"code": "NY", "name": "New York"}, {"code": "NY", "name": "Penn"}]}`, "duplicate station code"},
+		{`{"stations": [{"code": "SE", "name": "Secaucus"}, {"code": "TS", "name": "secaucus"}]}`, "used by both SE and TS"},
+	} {
+		if _, err := loadStations([]byte(r.data)); err == nil || !strings.Contains(err.Error(), r.want) {
+			t.Errorf("loadStations(%s) got error %v want %q", r.data, err, r.want)
+		}
+	}
+}
+
+func TestLoadStationsAccessibility(t *testing.T) {
+	r, err := loadStations([]byte(`{"version": "1", "stations": [
+		{"code": "NY", "name": "New York", "lines": ["NE"], "accessible": true},
+		{"code": "AS", "name": "Anderson Street", "lines": ["PV"], "accessible": false},
+		{"code": "XC", "name": "Cranford", "lines": ["RV"]}
+	]}`))
+	if err != nil {
+		t.Fatalf("loadStations() error: %v", err)
+	}
+	for _, want := range []struct {
+		code   string
+		access Accessibility
+	}{
+		{"NY", Accessible},
+		{"AS", NotAccessible},
+		{"XC", AccessibilityUnknown},
+	} {
+		if got := r.byCode[want.code].Accessibility; got != want.access {
+			t.Errorf("station %s got %v want %v", want.code, got, want.access)
+		}
+	}
+}
+
+func TestLookupStation(t *testing.T) {
+	for _, r := range []struct {
+		s    string
+		want string
+	}{
+		{"NY", "NY"},
+		{" ny ", "NY"},
+		{"New York", "NY"},
+		{"new york penn  STATION", "NY"},
+		{"Secaucus Lower Lvl", "TS"},
+		{"Aberdeen-Matawan", "AM"},
+		{"Secaucus", ""},
+		{"Baltimore", ""},
+	} {
+		got, ok := LookupStation(r.s)
+		if r.want == "" {
+			if ok {
+				t.Errorf("LookupStation(%q) got %s want none", r.s, got.Code)
+			}
+			continue
+		}
+		if !ok || got.Code != r.want {
+			t.Errorf("LookupStation(%q) got %v, %v want %s", r.s, got, ok, r.want)
+		}
+	}
+
+	hb, _ := LookupStation("HB")
+	if hb.Accessibility != Accessible || len(hb.Lines) == 0 || hb.Lines[0] != lineMorristown {
+		t.Errorf("LookupStation(HB) got %+v", hb)
+	}
+}
+
+func TestStationsAreCopies(t *testing.T) {
+	want, _ := LookupStation("NY")
+	looked, _ := LookupStation("NY")
+	for _, got := range append(Stations(), NearestStations(want.LatLng, 1)[0], looked) {
+		got.Name = "Changed"
+		if len(got.Aliases) > 0 {
+			got.Aliases[0] = "Changed"
+		}
+		if len(got.Lines) > 0 {
+			got.Lines[0] = Line{}
+		}
+	}
+	if got, _ := LookupStation("NY"); !cmp.Equal(want, got) {
+		t.Errorf("changing returned stations changed the dataset: got %+v want %+v", got, want)
+	}
+}
+
+func TestNearestStations(t *testing.T) {
+	// Near the Hoboken terminal.
+	got := NearestStations(LatLng{Lat: 40.7358, Lng: -74.0290}, 1)
+	if len(got) != 1 || got[0].Code != "HB" {
+		t.Errorf("NearestStations() got %v want HB", got)
+	}
+	if got := NearestStations(LatLng{}, -1); len(got) != 0 {
+		t.Errorf("NearestStations(n=-1) got %d stations want 0", len(got))
+	}
+	if got := NearestStations(LatLng{}, 1000); len(got) != len(Stations()) {
+		t.Errorf("NearestStations(n=1000) got %d stations want all %d", len(got), len(Stations()))
+	}
+}
+
+func TestLatLngDistance(t *testing.T) {
+	ny, np := LatLng{Lat: 40.7506, Lng: -73.9935}, LatLng{Lat: 40.7347, Lng: -74.1644}
+	// New York Penn Station to Newark Penn Station is about 14.5km.
+	if d := ny.Distance(np); math.Abs(d-14500) > 500 {
+		t.Errorf("Distance() got %.0fm want about 14500m", d)
+	}
+	if d := ny.Distance(ny); d != 0 {
+		t.Errorf("Distance() to itself got %f want 0", d)
+	}
+}
@@ -0,0 +1,102 @@
+package njtapi
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StatusKind classifies a TrainStatus.
+type StatusKind int
+
+// Kinds of train status.
+const (
+	StatusUnknown   StatusKind = iota // Empty or unrecognized status
+	StatusOnTime                      // Running on time, like "On Time" or "in 4 Min"
+	StatusBoarding                    // Boarding at the station
+	StatusAllAboard                   // About to depart the station
+	StatusDelayed                     // Running late, like "Delayed" or "10 Min Late"
+	StatusCancelled                   // Not running
+	StatusDeparted                    // Already left the station
+)
+
+func (k StatusKind) String() string {
+	switch k {
+	case StatusOnTime:
+		return "OnTime"
+	case StatusBoarding:
+		return "Boarding"
+	case StatusAllAboard:
+		return "AllAboard"
+	case StatusDelayed:
+		return "Delayed"
+	case StatusCancelled:
+		return "Cancelled"
+	case StatusDeparted:
+		return "Departed"
+	}
+	return "Unknown"
+}
+
+// A TrainStatus is a parsed departure board status.
+type TrainStatus struct {
+	Kind    StatusKind // Kind of status
+	Minutes int        // Minutes until departure for "in N Min", or minutes late for delays; 0 if not given
+	Text    string     // Status text as sent by the API, without surrounding whitespace
+}
+
+// String returns the status text as sent by the API.
+func (s TrainStatus) String() string {
+	return s.Text
+}
+
+var (
+	statusCountdownRe = regexp.MustCompile(`^in (\d+) ?mins?$`)
+	statusHoursRe     = regexp.MustCompile(`(\d+) ?(?:h|hr|hrs|hour|hours)\b`)
+	statusMinutesRe   = regexp.MustCompile(`(\d+) ?(?:m|min|mins|minute|minutes)\b`)
+)
+
+// ParseTrainStatus parses status text from a departure board, like
+// "in 4 Min", "BOARDING" or "2HR 15M LATE". Text which is empty or not
+// recognized has StatusUnknown.
+func ParseTrainStatus(text string) TrainStatus {
+	text = strings.TrimSpace(text)
+	s := TrainStatus{Text: text}
+	norm := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+
+	switch {
+	case norm == "":
+	case strings.Contains(norm, "cancel"):
+		s.Kind = StatusCancelled
+	case strings.Contains(norm, "all aboard"):
+		s.Kind = StatusAllAboard
+	case strings.Contains(norm, "boarding"):
+		s.Kind = StatusBoarding
+	case strings.Contains(norm, "departed"):
+		s.Kind = StatusDeparted
+	case norm == "on time" || norm == "ontime":
+		s.Kind = StatusOnTime
+	case statusCountdownRe.MatchString(norm):
+		s.Kind = StatusOnTime
+		s.Minutes, _ = strconv.Atoi(statusCountdownRe.FindStringSubmatch(norm)[1])
+	case strings.Contains(norm, "delay") || strings.Contains(norm, "late"):
+		s.Kind = StatusDelayed
+		s.Minutes = statusDelayMinutes(norm)
+	}
+	return s
+}
+
+// statusDelayMinutes totals the hours and minutes in a delay like
+// "2hr 15m late", returning 0 if there are none.
+func statusDelayMinutes(norm string) int {
+	minutes := 0
+	if m := statusHoursRe.FindStringSubmatch(norm); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		minutes += 60 * h
+	}
+	if m := statusMinutesRe.FindStringSubmatch(norm); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		minutes += n
+	}
+	return minutes
+}
@@ -0,0 +1,100 @@
+package njtapi
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// statusTests covers status text seen in captured departure board feeds,
+// for both trains and stops.
+var statusTests = []struct {
+	text string
+	want TrainStatus
+}{
+	{"", TrainStatus{}},
+	{"  \n  ", TrainStatus{}},
+	{"On Time", TrainStatus{Kind: StatusOnTime, Text: "On Time"}},
+	{"ON TIME", TrainStatus{Kind: StatusOnTime, Text: "ON TIME"}},
+	{"OnTime", TrainStatus{Kind: StatusOnTime, Text: "OnTime"}},
+	{"in 4 Min", TrainStatus{Kind: StatusOnTime, Minutes: 4, Text: "in 4 Min"}},
+	{"in 12 Min", TrainStatus{Kind: StatusOnTime, Minutes: 12, Text: "in 12 Min"}},
+	{"In 1 Min", TrainStatus{Kind: StatusOnTime, Minutes: 1, Text: "In 1 Min"}},
+	{"in 0 Min", TrainStatus{Kind: StatusOnTime, Text: "in 0 Min"}},
+	{"BOARDING", TrainStatus{Kind: StatusBoarding, Text: "BOARDING"}},
+	{"Boarding", TrainStatus{Kind: StatusBoarding, Text: "Boarding"}},
+	{"Now Boarding", TrainStatus{Kind: StatusBoarding, Text: "Now Boarding"}},
+	{"ALL ABOARD", TrainStatus{Kind: StatusAllAboard, Text: "ALL ABOARD"}},
+	{"All Aboard", TrainStatus{Kind: StatusAllAboard, Text: "All Aboard"}},
+	{"All  Aboard ", TrainStatus{Kind: StatusAllAboard, Text: "All  Aboard"}},
+	{"Delayed", TrainStatus{Kind: StatusDelayed, Text: "Delayed"}},
+	{"DELAYED", TrainStatus{Kind: StatusDelayed, Text: "DELAYED"}},
+	{"Delayed 15 Min", TrainStatus{Kind: StatusDelayed, Minutes: 15, Text: "Delayed 15 Min"}},
+	{"Late", TrainStatus{Kind: StatusDelayed, Text: "Late"}},
+	{"Running Late", TrainStatus{Kind: StatusDelayed, Text: "Running Late"}},
+	{"10 Min Late", TrainStatus{Kind: StatusDelayed, Minutes: 10, Text: "10 Min Late"}},
+	{"5 MINS LATE", TrainStatus{Kind: StatusDelayed, Minutes: 5, Text: "5 MINS LATE"}},
+	{"2HR 15M LATE", TrainStatus{Kind: StatusDelayed, Minutes: 135, Text: "2HR 15M LATE"}},
+	{"2 HOURS LATE", TrainStatus{Kind: StatusDelayed, Minutes: 120, Text: "2 HOURS LATE"}},
+	{"1 Hour 5 Minutes Late", TrainStatus{Kind: StatusDelayed, Minutes: 65, Text: "1 Hour 5 Minutes Late"}},
+	{"Cancelled", TrainStatus{Kind: StatusCancelled, Text: "Cancelled"}},
+	{"CANCELLED", TrainStatus{Kind: StatusCancelled, Text: "CANCELLED"}},
+	{"Canceled", TrainStatus{Kind: StatusCancelled, Text: "Canceled"}},
+	{"Departed", TrainStatus{Kind: StatusDeparted, Text: "Departed"}},
+	{"DEPARTED", TrainStatus{Kind: StatusDeparted, Text: "DEPARTED"}},
+	{"STAND BY", TrainStatus{Kind: StatusUnknown, Text: "STAND BY"}},
+	{"See Agent", TrainStatus{Kind: StatusUnknown, Text: "See Agent"}},
+}
+
+func TestParseTrainStatus(t *testing.T) {
+	for _, r := range statusTests {
+		if got := ParseTrainStatus(r.text); got != r.want {
+			t.Errorf("ParseTrainStatus(%q) got %+v want %+v", r.text, got, r.want)
+		}
+	}
+}
+
+// TestStatusTestsCoverFixtures ensures every status in the captured feeds
+// under testdata has a case in statusTests.
+func TestStatusTestsCoverFixtures(t *testing.T) {
+	covered := map[string]bool{}
+	for _, r := range statusTests {
+		covered[strings.TrimSpace(r.text)] = true
+	}
+
+	files, err := filepath.Glob("testdata/getTrainSchedule*.xml")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Glob() got %v, %v", files, err)
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error: %v", f, err)
+		}
+		var data stationDataWire
+		if err := xml.Unmarshal(b, &data); err != nil {
+			t.Fatalf("Unmarshal(%s) error: %v", f, err)
+		}
+		for _, item := range data.Items {
+			statuses := []string{item.Status}
+			for _, s := range item.Stops {
+				statuses = append(statuses, s.Status)
+			}
+			for _, s := range statuses {
+				if !covered[strings.TrimSpace(s)] {
+					t.Errorf("%s: status %q has no case in statusTests", f, s)
+				}
+			}
+		}
+	}
+}
+
+func TestStatusKindString(t *testing.T) {
+	for k := StatusUnknown; k <= StatusDeparted; k++ {
+		if k != StatusUnknown && k.String() == "Unknown" {
+			t.Errorf("StatusKind(%d).String() is missing a name", k)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package njtapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthMode selects how the client authenticates with the API.
+type AuthMode int
+
+const (
+	// AuthQuery sends the username and password in the query string of GET
+	// requests to the legacy .asmx XML endpoints. This is the default.
+	AuthQuery AuthMode = iota
+	// AuthToken exchanges the username and password for a token via the
+	// TrainData API's getToken endpoint, then form-POSTs every request
+	// with that token.
+	AuthToken
+)
+
+const (
+	getTokenEndpoint     = "getToken"
+	isValidTokenEndpoint = "isValidToken"
+
+	// tokenRevalidateAfter is how long a token is used before it is
+	// checked with isValidToken again.
+	tokenRevalidateAfter = time.Hour
+)
+
+// ErrAuthentication is returned when the API rejects the supplied credentials.
+var ErrAuthentication = errors.New("authentication failed")
+
+// tokenEndpoints maps legacy endpoint names to their TrainData API equivalents.
+var tokenEndpoints = map[string]string{
+	stationDataEndpoint: "getTrainSchedule",
+	stationListEndpoint: "getStationList",
+	trainMapEndpoint:    "getTrainMap",
+	trainStopsEndpoint:  "getTrainStopList",
+	vehicleDataEndpoint: "getVehicleData",
+}
+
+// tokenParams maps legacy parameter names to their TrainData API equivalents.
+var tokenParams = map[string]string{
+	"trainID": "train",
+}
+
+// tokenCache holds the current TrainData API token.
+type tokenCache struct {
+	mu      sync.Mutex
+	token   string
+	checked time.Time // When the token was last issued or validated
+
+	// refresh shares a token request among concurrent callers, each of
+	// which can give up on its own context while it is in flight.
+	refresh flightGroup
+}
+
+// token returns a usable token, requesting or revalidating one as needed.
+func (c *Client) token(ctx context.Context) (string, error) {
+	c.tokens.mu.Lock()
+	tok, checked := c.tokens.token, c.tokens.checked
+	c.tokens.mu.Unlock()
+	if tok != "" && time.Since(checked) < tokenRevalidateAfter {
+		return tok, nil
+	}
+
+	v, err := c.tokens.refresh.do(ctx, "token", func(ctx context.Context) (any, error) {
+		return c.refreshToken(ctx, tok)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// refreshToken revalidates tok, if set, or else requests a new token. The
+// token cache is not locked during the requests.
+func (c *Client) refreshToken(ctx context.Context, tok string) (string, error) {
+	if tok != "" {
+		if ok, err := c.isValidToken(ctx, tok); err == nil && ok {
+			c.tokens.mu.Lock()
+			if c.tokens.token == tok {
+				c.tokens.checked = time.Now()
+			}
+			c.tokens.mu.Unlock()
+			return tok, nil
+		}
+	}
+
+	tok, err := c.getToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.tokens.mu.Lock()
+	c.tokens.token = tok
+	c.tokens.checked = time.Now()
+	c.tokens.mu.Unlock()
+	return tok, nil
+}
+
+// invalidateToken discards tok so the next request obtains a new one.
+// It is a no-op if the token has already been replaced.
+func (c *Client) invalidateToken(tok string) {
+	c.tokens.mu.Lock()
+	defer c.tokens.mu.Unlock()
+	if c.tokens.token == tok {
+		c.tokens.token = ""
+	}
+}
+
+// getToken exchanges the client's credentials for a new token.
+func (c *Client) getToken(ctx context.Context) (string, error) {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := c.wait(ctx, getTokenEndpoint); err != nil {
+		return "", err
+	}
+	resp, err := c.post(ctx, getTokenEndpoint, url.Values{
+		"username": {creds.Username},
+		"password": {creds.Password},
+	})
+	if err != nil {
+		return "", err
+	}
+	data := struct {
+		Authenticated string
+		UserToken     string
+	}{}
+	if err := json.Unmarshal(resp.body, &data); err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(data.Authenticated, "true") || data.UserToken == "" {
+		return "", ErrAuthentication
+	}
+	return data.UserToken, nil
+}
+
+// isValidToken asks the API whether tok is still accepted.
+func (c *Client) isValidToken(ctx context.Context, tok string) (bool, error) {
+	if err := c.wait(ctx, isValidTokenEndpoint); err != nil {
+		return false, err
+	}
+	resp, err := c.post(ctx, isValidTokenEndpoint, url.Values{"token": {tok}})
+	if err != nil {
+		return false, err
+	}
+	data := struct {
+		ValidToken string
+	}{}
+	if err := json.Unmarshal(resp.body, &data); err != nil {
+		return false, err
+	}
+	return strings.EqualFold(data.ValidToken, "true"), nil
+}
+
+// postWithToken calls a data endpoint using the TrainData API. If the token
+// is rejected, a new one is requested and the call is made once more.
+func (c *Client) postWithToken(ctx context.Context, endpoint string, params map[string]string) (*response, error) {
+	name, ok := tokenEndpoints[endpoint]
+	if !ok {
+		name = strings.TrimSuffix(endpoint, "XML")
+	}
+	form := url.Values{}
+	for k, v := range params {
+		if n, ok := tokenParams[k]; ok {
+			k = n
+		}
+		form.Set(k, v)
+	}
+
+	for attempt := 0; ; attempt++ {
+		tok, err := c.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		form.Set("token", tok)
+
+		// Gate on the legacy name so per-endpoint limits apply in both modes.
+		if err := c.wait(ctx, endpoint); err != nil {
+			return nil, err
+		}
+		resp, err := c.post(ctx, name, form)
+		var apiErr *APIError
+		if attempt == 0 && errors.As(err, &apiErr) &&
+			(apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+			c.invalidateToken(tok)
+			continue
+		}
+		return resp, err
+	}
+}
+
+// post form-POSTs to a TrainData API endpoint and returns the response.
+func (c *Client) post(ctx context.Context, endpoint string, form url.Values) (*response, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, secrets{}.redactError(err)
+	}
+	s := secrets{form.Get("password"), form.Get("token")}
+	req, err := http.NewRequest("POST", u.JoinPath(endpoint).String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, s.redactError(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	params := map[string]string{}
+	for k := range form {
+		if !isSecretParam(k) {
+			params[k] = form.Get(k)
+		}
+	}
+	return c.do(ctx, &Request{Endpoint: endpoint, Params: params, HTTP: req}, s)
+}
@@ -0,0 +1,268 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeTokenAPI mimics the token handling of the TrainData API.
+type fakeTokenAPI struct {
+	t       *testing.T
+	fixture string // File served by data endpoints; the request is echoed if empty
+
+	mu          sync.Mutex
+	issued      int    // Number of tokens issued
+	valid       string // The only token currently accepted
+	validations int    // Number of isValidToken calls
+	requests    map[string]int
+}
+
+func (f *fakeTokenAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method != http.MethodPost {
+		f.t.Errorf("%s %s: expected POST", r.Method, r.URL.Path)
+	}
+	if err := r.ParseForm(); err != nil {
+		f.t.Errorf("Error parsing request: %v", err)
+	}
+	if f.requests == nil {
+		f.requests = map[string]int{}
+	}
+	f.requests[r.URL.Path]++
+
+	switch r.URL.Path {
+	case "/getToken":
+		if r.PostForm.Get("username") != "username" || r.PostForm.Get("password") != "pa$$word" {
+			_, _ = w.Write([]byte(`{"Authenticated":"False","UserToken":""}`))
+			return
+		}
+		f.issued++
+		f.valid = fmt.Sprintf("token-%d", f.issued)
+		fmt.Fprintf(w, `{"Authenticated":"True","UserToken":%q}`, f.valid)
+	case "/isValidToken":
+		f.validations++
+		fmt.Fprintf(w, `{"ValidToken":"%t","UserID":"username"}`, r.PostForm.Get("token") == f.valid)
+	default:
+		if r.PostForm.Get("token") != f.valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if f.fixture != "" {
+			http.ServeFile(w, r, f.fixture)
+			return
+		}
+		fmt.Fprintf(w, `{"path":%q,"train":%q}`, r.URL.Path, r.PostForm.Get("train"))
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	api := &fakeTokenAPI{t: t}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetAuthMode(AuthToken)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		resp, err := c.fetch(ctx, trainStopsEndpoint, map[string]string{"trainID": "1085"})
+		if err != nil {
+			t.Fatalf("fetch() unexpected error: %v", err)
+		}
+		if want := `{"path":"/getTrainStopList","train":"1085"}`; string(resp.body) != want {
+			t.Errorf("fetch() got %s want %s", resp.body, want)
+		}
+	}
+	if api.issued != 1 {
+		t.Errorf("expected 1 token to be issued, got %d", api.issued)
+	}
+
+	// The server forgets the token; the client should transparently get a new one.
+	api.valid = "expired"
+	if _, err := c.fetch(ctx, vehicleDataEndpoint, nil); err != nil {
+		t.Fatalf("fetch() after token expiry unexpected error: %v", err)
+	}
+	if api.issued != 2 {
+		t.Errorf("expected 2 tokens to be issued, got %d", api.issued)
+	}
+	if api.requests["/getVehicleData"] != 2 {
+		t.Errorf("expected getVehicleData to be retried once, got %d calls", api.requests["/getVehicleData"])
+	}
+}
+
+func TestTokenAuthStationList(t *testing.T) {
+	api := &fakeTokenAPI{t: t, fixture: "testdata/getStationList.json"}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetAuthMode(AuthToken)
+
+	got, err := c.StationList(context.Background())
+	if err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+	if len(got) != 6 || got[0].ID != "NY" || got[0].Name != "New York" {
+		t.Errorf("StationList() got %+v want the 6 stations from the JSON response", got)
+	}
+	if api.issued != 1 || api.requests["/getStationList"] != 1 {
+		t.Errorf("expected 1 token and 1 getStationList call, got %d and %d", api.issued, api.requests["/getStationList"])
+	}
+}
+
+func TestTokenRevalidation(t *testing.T) {
+	api := &fakeTokenAPI{t: t}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetAuthMode(AuthToken)
+	ctx := context.Background()
+
+	if _, err := c.fetch(ctx, stationListEndpoint, nil); err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+
+	// Pretend the token was issued long ago so it is checked before use.
+	c.tokens.checked = time.Now().Add(-2 * tokenRevalidateAfter)
+	if _, err := c.fetch(ctx, stationListEndpoint, nil); err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+	if api.validations != 1 || api.issued != 1 {
+		t.Errorf("expected 1 validation and 1 token, got %d and %d", api.validations, api.issued)
+	}
+
+	// A token that fails validation is replaced.
+	c.tokens.checked = time.Now().Add(-2 * tokenRevalidateAfter)
+	api.valid = "revoked"
+	if _, err := c.fetch(ctx, stationListEndpoint, nil); err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+	if api.validations != 2 || api.issued != 2 {
+		t.Errorf("expected 2 validations and 2 tokens, got %d and %d", api.validations, api.issued)
+	}
+}
+
+func TestTokenBadCredentials(t *testing.T) {
+	ts := httptest.NewServer(&fakeTokenAPI{t: t})
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "wrong")
+	c.SetAuthMode(AuthToken)
+
+	if _, err := c.fetch(context.Background(), stationListEndpoint, nil); !errors.Is(err, ErrAuthentication) {
+		t.Errorf("fetch() got %v want ErrAuthentication", err)
+	}
+}
+
+// recordingLimiter lets every request through, noting which endpoint it was for.
+type recordingLimiter struct {
+	mu    sync.Mutex
+	waits []string
+}
+
+func (l *recordingLimiter) Wait(_ context.Context, endpoint string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waits = append(l.waits, endpoint)
+	return nil
+}
+
+func TestTokenLimiter(t *testing.T) {
+	api := &fakeTokenAPI{t: t}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	l := &recordingLimiter{}
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetAuthMode(AuthToken)
+	c.SetLimiter(l)
+	ctx := context.Background()
+
+	if _, err := c.fetch(ctx, vehicleDataEndpoint, nil); err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+	// A rejected token is replaced and the request retried.
+	api.valid = "expired"
+	if _, err := c.fetch(ctx, vehicleDataEndpoint, nil); err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+	// A stale token is revalidated.
+	c.tokens.checked = time.Now().Add(-2 * tokenRevalidateAfter)
+	if _, err := c.fetch(ctx, vehicleDataEndpoint, nil); err != nil {
+		t.Fatalf("fetch() unexpected error: %v", err)
+	}
+
+	want := []string{
+		getTokenEndpoint, vehicleDataEndpoint,
+		vehicleDataEndpoint, getTokenEndpoint, vehicleDataEndpoint,
+		isValidTokenEndpoint, vehicleDataEndpoint,
+	}
+	if diff := cmp.Diff(want, l.waits); diff != "" {
+		t.Errorf("Limiter waits mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// gateLimiter blocks getToken requests until released.
+type gateLimiter struct {
+	release chan struct{}
+}
+
+func (l *gateLimiter) Wait(ctx context.Context, endpoint string) error {
+	if endpoint != getTokenEndpoint {
+		return nil
+	}
+	select {
+	case <-l.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestTokenRefreshHonorsContext(t *testing.T) {
+	api := &fakeTokenAPI{t: t}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	l := &gateLimiter{release: make(chan struct{})}
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetAuthMode(AuthToken)
+	c.SetLimiter(l)
+
+	// One caller waits on the limiter for a token...
+	blocked := make(chan error)
+	go func() {
+		_, err := c.fetch(context.Background(), stationListEndpoint, nil)
+		blocked <- err
+	}()
+
+	// ...while another gives up on its own deadline instead of queueing.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.fetch(ctx, stationListEndpoint, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("fetch() got %v want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("fetch() took %v to honor its deadline", elapsed)
+	}
+
+	close(l.release)
+	if err := <-blocked; err != nil {
+		t.Errorf("fetch() unexpected error: %v", err)
+	}
+	if api.issued != 1 {
+		t.Errorf("expected 1 token to be issued, got %d", api.issued)
+	}
+}
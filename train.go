@@ -0,0 +1,196 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TrainSource identifies the method a Train field was taken from.
+type TrainSource int
+
+// Sources of a MergedTrain's fields.
+const (
+	SourceNone        TrainSource = iota // No source had the field
+	SourceTrainMap                       // GetTrainMap
+	SourceTrainStops                     // GetTrainStops
+	SourceVehicleData                    // VehicleData
+)
+
+func (s TrainSource) String() string {
+	switch s {
+	case SourceTrainMap:
+		return "GetTrainMap"
+	case SourceTrainStops:
+		return "GetTrainStops"
+	case SourceVehicleData:
+		return "VehicleData"
+	}
+	return "none"
+}
+
+// FieldSource records where a merged field came from and how fresh it is.
+type FieldSource struct {
+	Source       TrainSource // Method the field was taken from
+	LastModified time.Time   // Time that source last updated the train
+	Stale        bool        // The source was served from the cache because the API could not be reached
+}
+
+// TrainSources records the source of each field of a MergedTrain.
+type TrainSources struct {
+	Line                   FieldSource
+	Direction              FieldSource
+	LastModified           FieldSource
+	ScheduledDepartureTime FieldSource
+	SecondsLate            FieldSource
+	NextStop               FieldSource
+	LatLng                 FieldSource
+	TrackCircuit           FieldSource
+	Stops                  FieldSource
+}
+
+// A MergedTrain is a Train assembled from every method that describes it.
+type MergedTrain struct {
+	Train
+	Sources TrainSources          // Where each field came from
+	Errors  map[TrainSource]error // Methods that failed, if the train was found by the others
+}
+
+// GetTrain returns everything known about a train, combining GetTrainMap,
+// GetTrainStops and VehicleData, which are fetched concurrently.
+//
+// The position and LastModified come from whichever source updated the
+// train most recently. Line, Direction and TrackCircuit prefer the train
+// map, Stops come from the stop list, and the delay, next stop and
+// scheduled departure come from the vehicle data.
+//
+// A method failing is tolerated as long as another one finds the train; its
+// error is kept in Errors. ErrTrainNotFound is returned if no method has
+// the train.
+func (c *Client) GetTrain(ctx context.Context, trainID TrainID) (*MergedTrain, error) {
+	params := map[string]string{"trainID": trainID.String()}
+	info := CallInfo{Method: "GetTrain", TrainID: trainID}
+	return invoke(ctx, c, info, params, func(ctx context.Context) (*MergedTrain, error) {
+		return c.getTrain(ctx, trainID)
+	})
+}
+
+func (c *Client) getTrain(ctx context.Context, trainID TrainID) (*MergedTrain, error) {
+	results := make([]sourcedTrain, 3)
+	var wg sync.WaitGroup
+	wg.Add(len(results))
+	go func() {
+		defer wg.Done()
+		t, err := c.GetTrainMap(ctx, trainID)
+		results[0] = sourcedTrain{SourceTrainMap, t, err}
+	}()
+	go func() {
+		defer wg.Done()
+		t, err := c.GetTrainStops(ctx, trainID)
+		results[1] = sourcedTrain{SourceTrainStops, t, err}
+	}()
+	go func() {
+		defer wg.Done()
+		trains, err := c.VehicleData(ctx)
+		results[2] = sourcedTrain{SourceVehicleData, findTrain(trains, trainID), err}
+	}()
+	wg.Wait()
+	return mergeTrain(trainID, results)
+}
+
+// sourcedTrain is the result of one of the methods GetTrain combines.
+type sourcedTrain struct {
+	source TrainSource
+	train  *Train
+	err    error
+}
+
+func (r sourcedTrain) fieldSource() FieldSource {
+	return FieldSource{Source: r.source, LastModified: r.train.LastModified, Stale: r.train.Stale}
+}
+
+// findTrain returns the train with the given ID, or nil. IDs are compared
+// in canonical form, so "6659." finds train 6659.
+func findTrain(trains []Train, trainID TrainID) *Train {
+	for i, t := range trains {
+		if t.ID.String() == trainID.String() {
+			return &trains[i]
+		}
+	}
+	return nil
+}
+
+// mergeTrain combines results, which are in order of precedence.
+func mergeTrain(trainID TrainID, results []sourcedTrain) (*MergedTrain, error) {
+	m := &MergedTrain{Train: Train{ID: ParseTrainID(trainID.String())}}
+	var found []sourcedTrain
+	var errs []error
+	for _, r := range results {
+		switch {
+		case r.err != nil && !errors.Is(r.err, ErrTrainNotFound):
+			if m.Errors == nil {
+				m.Errors = map[TrainSource]error{}
+			}
+			m.Errors[r.source] = r.err
+			errs = append(errs, r.err)
+		case r.err == nil && r.train != nil:
+			found = append(found, r)
+		}
+	}
+	if len(found) == 0 {
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return nil, ErrTrainNotFound
+	}
+
+	// freshest is found ordered by most recently updated.
+	freshest := append([]sourcedTrain(nil), found...)
+	sort.SliceStable(freshest, func(i, j int) bool {
+		return freshest[i].train.LastModified.After(freshest[j].train.LastModified)
+	})
+	first := func(candidates []sourcedTrain, has func(*Train) bool) (*Train, FieldSource) {
+		for _, r := range candidates {
+			if has(r.train) {
+				return r.train, r.fieldSource()
+			}
+		}
+		return nil, FieldSource{}
+	}
+
+	if t, src := first(found, func(t *Train) bool { return t.Line != (Line{}) }); t != nil {
+		m.Line, m.Sources.Line = t.Line, src
+	}
+	if t, src := first(found, func(t *Train) bool { return t.Direction != "" }); t != nil {
+		m.Direction, m.Sources.Direction = t.Direction, src
+	}
+	if t, src := first(found, func(t *Train) bool { return t.TrackCircuit != "" }); t != nil {
+		m.TrackCircuit, m.Sources.TrackCircuit = t.TrackCircuit, src
+	}
+	if t, src := first(freshest, func(t *Train) bool { return !t.LastModified.IsZero() }); t != nil {
+		m.LastModified, m.Sources.LastModified = t.LastModified, src
+	}
+	if t, src := first(freshest, func(t *Train) bool { return t.LatLng != nil }); t != nil {
+		m.LatLng, m.Sources.LatLng = t.LatLng, src
+	}
+	if t, src := first(found, func(t *Train) bool { return t.Stops != nil }); t != nil {
+		m.Stops, m.Sources.Stops = t.Stops, src
+	}
+	if t, src := first(found, func(t *Train) bool { return !t.ScheduledDepartureTime.IsZero() }); t != nil {
+		m.ScheduledDepartureTime, m.Sources.ScheduledDepartureTime = t.ScheduledDepartureTime, src
+	}
+	if t, src := first(found, func(t *Train) bool { return t.NextStop != "" }); t != nil {
+		m.NextStop, m.Sources.NextStop = t.NextStop, src
+	}
+	for _, r := range found {
+		// Only the vehicle data reports delays, and zero means on time.
+		if r.source == SourceVehicleData {
+			m.SecondsLate, m.Sources.SecondsLate = r.train.SecondsLate, r.fieldSource()
+		}
+		m.ParseErrors = append(m.ParseErrors, r.train.ParseErrors...)
+		m.Stale = m.Stale || r.train.Stale
+	}
+	return m, nil
+}
@@ -0,0 +1,81 @@
+package njtapi
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// trainIDRe finds the number within a train identifier.
+var trainIDRe = regexp.MustCompile(`(\d+)`)
+
+// An Operator is the railroad running a train.
+type Operator int
+
+// Operators seen in NJTransit data.
+const (
+	OperatorOther  Operator = iota // Any other railroad, or an unrecognized ID
+	OperatorNJT                    // NJTransit
+	OperatorAmtrak                 // Amtrak
+)
+
+func (o Operator) String() string {
+	switch o {
+	case OperatorNJT:
+		return "NJT"
+	case OperatorAmtrak:
+		return "Amtrak"
+	}
+	return "Other"
+}
+
+// A TrainID identifies a train. The API identifies NJTransit trains by
+// number, like "3883", and Amtrak trains with an "A" prefix, like "A137",
+// or an "a" suffix. Some feeds add stray punctuation, like "6659.".
+type TrainID struct {
+	Raw      string   // Identifier as sent by the API, without surrounding whitespace
+	Number   int      // Numeric part of the identifier, or 0 if there is none
+	Operator Operator // Railroad running the train
+}
+
+// ParseTrainID parses a train identifier from the API. Identifiers without
+// any digits have a zero Number and OperatorOther.
+//
+// https://github.com/bamnet/njtapi/issues/7
+func ParseTrainID(s string) TrainID {
+	id := TrainID{Raw: strings.TrimSpace(s)}
+	m := trainIDRe.FindStringSubmatchIndex(id.Raw)
+	if m == nil {
+		return id
+	}
+	id.Number, _ = strconv.Atoi(id.Raw[m[2]:m[3]])
+
+	// Letters around the number identify the operator; any other
+	// characters, like ".", are noise.
+	letters := strings.ToUpper(strings.Trim(id.Raw[:m[2]]+id.Raw[m[3]:], ". "))
+	switch letters {
+	case "":
+		id.Operator = OperatorNJT
+	case "A":
+		id.Operator = OperatorAmtrak
+	}
+	return id
+}
+
+// NJTTrainID returns the TrainID of the NJTransit train with number n.
+func NJTTrainID(n int) TrainID {
+	return TrainID{Raw: strconv.Itoa(n), Number: n, Operator: OperatorNJT}
+}
+
+// String returns a canonical form of the identifier, like "3883" or "A137".
+// Identifiers which are not recognized are returned as sent. This is also
+// the form passed to the API, so stray punctuation is never sent.
+func (id TrainID) String() string {
+	switch id.Operator {
+	case OperatorNJT:
+		return strconv.Itoa(id.Number)
+	case OperatorAmtrak:
+		return "A" + strconv.Itoa(id.Number)
+	}
+	return id.Raw
+}
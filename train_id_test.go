@@ -0,0 +1,38 @@
+package njtapi
+
+import "testing"
+
+func TestParseTrainID(t *testing.T) {
+	for _, r := range []struct {
+		in   string
+		want TrainID
+		str  string
+	}{
+		{"3883", TrainID{Raw: "3883", Number: 3883, Operator: OperatorNJT}, "3883"},
+		{" 41\n", TrainID{Raw: "41", Number: 41, Operator: OperatorNJT}, "41"},
+		{"6659.", TrainID{Raw: "6659.", Number: 6659, Operator: OperatorNJT}, "6659"},
+		{".5193", TrainID{Raw: ".5193", Number: 5193, Operator: OperatorNJT}, "5193"},
+		{"A137", TrainID{Raw: "A137", Number: 137, Operator: OperatorAmtrak}, "A137"},
+		{"a2150", TrainID{Raw: "a2150", Number: 2150, Operator: OperatorAmtrak}, "A2150"},
+		{"655a", TrainID{Raw: "655a", Number: 655, Operator: OperatorAmtrak}, "A655"},
+		{"X12", TrainID{Raw: "X12", Number: 12, Operator: OperatorOther}, "X12"},
+		{"TBD", TrainID{Raw: "TBD"}, "TBD"},
+		{"", TrainID{}, ""},
+	} {
+		got := ParseTrainID(r.in)
+		if got != r.want {
+			t.Errorf("ParseTrainID(%q) got %+v want %+v", r.in, got, r.want)
+		}
+		if s := got.String(); s != r.str {
+			t.Errorf("ParseTrainID(%q).String() got %q want %q", r.in, s, r.str)
+		}
+	}
+}
+
+func TestOperatorString(t *testing.T) {
+	for o, want := range map[Operator]string{OperatorNJT: "NJT", OperatorAmtrak: "Amtrak", OperatorOther: "Other"} {
+		if got := o.String(); got != want {
+			t.Errorf("%d.String() got %q want %q", o, got, want)
+		}
+	}
+}
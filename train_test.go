@@ -0,0 +1,150 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bamnet/njtapi/njtapitest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeTrain(t *testing.T) {
+	t1 := time.Date(2024, 5, 3, 20, 7, 0, 0, time.UTC)
+	t2, t3 := t1.Add(time.Minute), t1.Add(2*time.Minute)
+	parseErr := &ParseError{Field: "LatLng", Value: "x,y", Err: errors.New("bad")}
+	stops := []StationStop{{Name: "Hoboken", StationID: "HB"}}
+
+	mapTrain := &Train{
+		ID: NJTTrainID(1085), Line: lineMorristown, Direction: "Westbound",
+		LastModified: t1, LatLng: &LatLng{Lat: 1, Lng: 1}, TrackCircuit: "MN-1",
+	}
+	stopsTrain := &Train{
+		ID: NJTTrainID(1085), LastModified: t3, LatLng: &LatLng{Lat: 3, Lng: 3}, Stops: stops,
+	}
+	vehicle := &Train{
+		ID: NJTTrainID(1085), Line: lineMorristown, Direction: "Eastbound",
+		LastModified: t2, LatLng: &LatLng{Lat: 2, Lng: 2}, TrackCircuit: "MN-2",
+		SecondsLate: time.Minute, NextStop: "Dover", ScheduledDepartureTime: t1,
+		ParseErrors: []error{parseErr}, Stale: true,
+	}
+
+	got, err := mergeTrain(NJTTrainID(1085), []sourcedTrain{
+		{SourceTrainMap, mapTrain, nil},
+		{SourceTrainStops, stopsTrain, nil},
+		{SourceVehicleData, vehicle, nil},
+	})
+	if err != nil {
+		t.Fatalf("mergeTrain() unexpected error: %v", err)
+	}
+	fromMap := FieldSource{Source: SourceTrainMap, LastModified: t1}
+	fromStops := FieldSource{Source: SourceTrainStops, LastModified: t3}
+	fromVehicle := FieldSource{Source: SourceVehicleData, LastModified: t2, Stale: true}
+	want := &MergedTrain{
+		Train: Train{
+			ID: NJTTrainID(1085), Line: lineMorristown, Direction: "Westbound",
+			LastModified: t3, ScheduledDepartureTime: t1, SecondsLate: time.Minute, NextStop: "Dover",
+			LatLng: &LatLng{Lat: 3, Lng: 3}, TrackCircuit: "MN-1", Stops: stops,
+			ParseErrors: []error{parseErr}, Stale: true,
+		},
+		Sources: TrainSources{
+			Line:                   fromMap,
+			Direction:              fromMap,
+			LastModified:           fromStops,
+			ScheduledDepartureTime: fromVehicle,
+			SecondsLate:            fromVehicle,
+			NextStop:               fromVehicle,
+			LatLng:                 fromStops,
+			TrackCircuit:           fromMap,
+			Stops:                  fromStops,
+		},
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b error) bool { return a == b })); diff != "" {
+		t.Errorf("mergeTrain() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMergeTrainErrors(t *testing.T) {
+	apiErr := &APIError{StatusCode: http.StatusInternalServerError}
+	otherErr := errors.New("connection reset")
+
+	if _, err := mergeTrain(NJTTrainID(1), []sourcedTrain{
+		{SourceTrainMap, nil, ErrTrainNotFound},
+		{SourceTrainStops, nil, ErrTrainNotFound},
+		{SourceVehicleData, nil, nil},
+	}); !errors.Is(err, ErrTrainNotFound) {
+		t.Errorf("mergeTrain(not found) got %v want ErrTrainNotFound", err)
+	}
+
+	_, err := mergeTrain(NJTTrainID(1), []sourcedTrain{
+		{SourceTrainMap, nil, apiErr},
+		{SourceTrainStops, nil, ErrTrainNotFound},
+		{SourceVehicleData, nil, otherErr},
+	})
+	if !errors.Is(err, apiErr) || !errors.Is(err, otherErr) {
+		t.Errorf("mergeTrain(failures) got %v want both errors", err)
+	}
+
+	got, err := mergeTrain(NJTTrainID(1), []sourcedTrain{
+		{SourceTrainMap, nil, apiErr},
+		{SourceTrainStops, &Train{ID: NJTTrainID(1), Stops: []StationStop{}}, nil},
+		{SourceVehicleData, nil, nil},
+	})
+	if err != nil {
+		t.Fatalf("mergeTrain(one failure) unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[TrainSource]error{SourceTrainMap: apiErr}, got.Errors, cmp.Comparer(func(a, b error) bool { return a == b })); diff != "" {
+		t.Errorf("Errors mismatch (-want +got):\n%s", diff)
+	}
+	if got.Sources.Line.Source != SourceNone || got.Sources.Stops.Source != SourceTrainStops {
+		t.Errorf("Sources got %+v", got.Sources)
+	}
+}
+
+func TestGetTrain(t *testing.T) {
+	srv := njtapitest.NewServer()
+	defer srv.Close()
+
+	departs := time.Date(2024, 5, 3, 20, 7, 0, 0, time.UTC)
+	ny := njtapitest.NewStation("NY", "New York Penn Station")
+	np := njtapitest.NewStation("NP", "Newark Penn Station")
+	srv.AddStations(ny, np)
+	train := njtapitest.NewTrain("3883", "Northeast Corridor Line", "NEC").
+		At(40.7706, -74.0403).
+		Stop(ny, departs).Stop(np, departs.Add(20*time.Minute)).
+		DepartedThrough("NY")
+	train.Direction = "Westbound"
+	train.Delay = 4 * time.Minute
+	train.LastModified = departs.Add(5 * time.Minute)
+	srv.AddTrains(train)
+	srv.InjectFault(njtapitest.TrainMapEndpoint, njtapitest.Fault{StatusCode: http.StatusInternalServerError})
+
+	c := NewClient(srv.URL, njtapitest.Username, njtapitest.Password)
+	got, err := c.GetTrain(context.Background(), NJTTrainID(3883))
+	if err != nil {
+		t.Fatalf("GetTrain() unexpected error: %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(got.Errors[SourceTrainMap], &apiErr) || len(got.Errors) != 1 {
+		t.Errorf("GetTrain() Errors got %v want only the train map to fail", got.Errors)
+	}
+	if got.Line != lineNortheastCorridor || got.Sources.Line.Source != SourceVehicleData {
+		t.Errorf("GetTrain() Line got %v from %v want the vehicle data", got.Line, got.Sources.Line.Source)
+	}
+	if len(got.Stops) != 2 || got.Sources.Stops.Source != SourceTrainStops {
+		t.Errorf("GetTrain() Stops got %+v", got.Stops)
+	}
+	if got.SecondsLate != 4*time.Minute || got.NextStop != "Newark Penn Station" || got.LatLng == nil {
+		t.Errorf("GetTrain() got %+v", got.Train)
+	}
+
+	if _, err := c.GetTrain(context.Background(), NJTTrainID(1)); !errors.As(err, &apiErr) {
+		t.Errorf("GetTrain(unknown) got %v want the train map failure", err)
+	}
+	srv.ClearFaults()
+	if _, err := c.GetTrain(context.Background(), NJTTrainID(1)); !errors.Is(err, ErrTrainNotFound) {
+		t.Errorf("GetTrain(unknown) got %v want ErrTrainNotFound", err)
+	}
+}
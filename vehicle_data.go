@@ -2,10 +2,8 @@ package njtapi
 
 import (
 	"context"
-	"encoding/xml"
 	"errors"
-	"regexp"
-	"strconv"
+	"log/slog"
 	"strings"
 	"time"
 )
@@ -16,16 +14,12 @@ const (
 	trainStopsEndpoint  = "getTrainStopListXML"
 )
 
-var (
-	ErrTrainNotFound = errors.New("train not found")
-
-	trainIDRe = regexp.MustCompile(`(\d+)`)
-)
+var ErrTrainNotFound = errors.New("train not found")
 
 // A Train summarizes the latest information about a train.
 type Train struct {
-	ID                     int           // Train number
-	Line                   string        // Train line
+	ID                     TrainID       // Train identifier
+	Line                   Line          // Train line, or zero if not sent
 	Direction              string        // Eastbound or Westbound
 	LastModified           time.Time     // ???
 	ScheduledDepartureTime time.Time     // ???
@@ -35,49 +29,47 @@ type Train struct {
 	TrackCircuit           string        // Track Circuit ID, like "CL-2WAK" or "BC-8251TK".
 	Stops                  []StationStop // Stations the train stops at.
 	ParseErrors            []error       // Errors encountered while parsing this train
+	Stale                  bool          // Served from the cache because the API could not be reached
 }
 
 // Get information about a specific train from the "Map" API endpoint.
+// Use NJTTrainID for NJTransit trains, or ParseTrainID for IDs like "A137"
+// reported by other methods.
 //
 // The `Train` object returned will not have all the fields set. It will
 // typically only have `ID`, `Line`, `Direction`, `LastModified`, `LatLng`,
-// and `TrackCircuit`.
-func (c *Client) GetTrainMap(ctx context.Context, trainID int) (*Train, error) {
-	resp, err := c.fetch(ctx, trainMapEndpoint, map[string]string{"trainID": strconv.Itoa(trainID), "station": "-"})
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		XMLName xml.Name `xml:"Trains"`
-		Trains  []struct {
-			ID           string `xml:"Train_ID"`
-			Line         string `xml:"TrainLine"`
-			Direction    string `xml:"DIRECTION"`
-			LastModified string `xml:"LAST_MODIFIED"`
-			Longitude    string `xml:"longitude"`
-			Latitude     string `xml:"latitude"`
-			TrackCircuit string `xml:"TrackCKT"`
-		} `xml:"Train"`
-	}{}
+// and `TrackCircuit`. Use GetTrain for all available fields.
+func (c *Client) GetTrainMap(ctx context.Context, trainID TrainID) (*Train, error) {
+	params := map[string]string{"trainID": trainID.String(), "station": "-"}
+	info := CallInfo{Method: "GetTrainMap", Endpoint: trainMapEndpoint, TrainID: trainID}
+	return invoke(ctx, c, info, params, func(ctx context.Context) (*Train, error) {
+		return c.getTrainMap(ctx, trainID, params)
+	})
+}
 
-	err = xml.Unmarshal(resp, &data)
+func (c *Client) getTrainMap(ctx context.Context, trainID TrainID, params map[string]string) (*Train, error) {
+	var data trainMapWire
+	resp, err := c.load(ctx, trainMapEndpoint, params, &data)
 	if err != nil {
 		return nil, err
 	}
 
 	// There is always 1 train returned, even when it doesn't exist.
 	// We use 'Direction' and 'Line' as good signals for a real train.
+	if len(data.Trains) == 0 {
+		return nil, ErrTrainNotFound
+	}
 	t := data.Trains[0]
 	if t.Direction == "" && t.Line == "" {
 		return nil, ErrTrainNotFound
 	}
 
 	train := Train{
-		ID:           trainID,
-		Line:         t.Line,
+		ID:           ParseTrainID(trainID.String()),
+		Line:         resolveLine("", t.Line),
 		Direction:    t.Direction,
 		TrackCircuit: t.TrackCircuit,
+		Stale:        resp.stale,
 	}
 	train.LastModified, err = c.parseTime(t.LastModified)
 	if err != nil {
@@ -92,6 +84,7 @@ func (c *Client) GetTrainMap(ctx context.Context, trainID int) (*Train, error) {
 			Field: "LatLng", Value: t.Latitude + "," + t.Longitude, Err: err,
 		})
 	}
+	c.logParseErrors(ctx, trainMapEndpoint, params["trainID"], train.ParseErrors)
 
 	return &train, nil
 }
@@ -99,35 +92,19 @@ func (c *Client) GetTrainMap(ctx context.Context, trainID int) (*Train, error) {
 // Get information about a specific train from the "Stops" API endpoint.
 //
 // The `Train` object returned will not have all the fields set. It will
-// typically only have `ID`, `LastModified`, `LatLng`, and `Stops`.
-func (c *Client) GetTrainStops(ctx context.Context, trainID int) (*Train, error) {
-	resp, err := c.fetch(ctx, trainStopsEndpoint, map[string]string{"trainID": strconv.Itoa(trainID)})
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		XMLName     xml.Name `xml:"Train"`
-		ID          string   `xml:"Train_ID"`
-		Destination string   `xml:"DESTINATION"`
-		GPSTime     string   `xml:"GPSTIME"`
-		Longitude   string   `xml:"GPSLONGITUDE"`
-		Latitude    string   `xml:"GPSLATITUDE"`
-		Stops       []struct {
-			Name          string `xml:"NAME"`
-			Station2Char  string `xml:"STATION_2CHAR"`
-			Time          string `xml:"TIME"`
-			Departed      string `xml:"DEPARTED"`
-			Status        string `xml:"STOP_STATUS"`
-			DepartureTime string `xml:"DEP_TIME"`
-			Lines         []struct {
-				Code string `xml:"LINE_CODE"`
-				Name string `xml:"LINE_NAME"`
-			} `xml:"STOP_LINES>STOP_LINE"`
-		} `xml:"STOPS>STOP"`
-	}{}
+// typically only have `ID`, `LastModified`, `LatLng`, and `Stops`. Use
+// GetTrain for all available fields.
+func (c *Client) GetTrainStops(ctx context.Context, trainID TrainID) (*Train, error) {
+	params := map[string]string{"trainID": trainID.String()}
+	info := CallInfo{Method: "GetTrainStops", Endpoint: trainStopsEndpoint, TrainID: trainID}
+	return invoke(ctx, c, info, params, func(ctx context.Context) (*Train, error) {
+		return c.getTrainStops(ctx, trainID, params)
+	})
+}
 
-	err = xml.Unmarshal(resp, &data)
+func (c *Client) getTrainStops(ctx context.Context, trainID TrainID, params map[string]string) (*Train, error) {
+	var data trainStopsWire
+	resp, err := c.load(ctx, trainStopsEndpoint, params, &data)
 	if err != nil {
 		return nil, err
 	}
@@ -137,8 +114,9 @@ func (c *Client) GetTrainStops(ctx context.Context, trainID int) (*Train, error)
 	}
 
 	train := Train{
-		ID:    trainID,
+		ID:    ParseTrainID(trainID.String()),
 		Stops: []StationStop{},
+		Stale: resp.stale,
 	}
 	train.LastModified, err = c.parseTime(data.GPSTime)
 	if err != nil {
@@ -160,6 +138,7 @@ func (c *Client) GetTrainStops(ctx context.Context, trainID int) (*Train, error)
 			Departed: (s.Departed == "YES"),
 			Status:   s.Status,
 		}
+		resolveStationID(&stop, s.Station2Char)
 		stop.Time, err = c.parseTime(s.Time)
 		if err != nil {
 			stop.ParseErrors = append(stop.ParseErrors, &ParseError{
@@ -176,60 +155,35 @@ func (c *Client) GetTrainStops(ctx context.Context, trainID int) (*Train, error)
 		if len(s.Lines) > 0 {
 			stop.Lines = make([]Line, len(s.Lines))
 			for i, l := range s.Lines {
-				stop.Lines[i] = Line{Name: l.Name}
+				stop.Lines[i] = resolveLine(l.Code, l.Name)
 			}
 		}
+		c.logParseErrors(ctx, trainStopsEndpoint, params["trainID"], stop.ParseErrors)
 		train.Stops = append(train.Stops, stop)
 	}
+	c.logParseErrors(ctx, trainStopsEndpoint, params["trainID"], train.ParseErrors)
 
 	return &train, nil
 }
 
 // VehicleData returns up the most recent information about all "active" trains.
 func (c *Client) VehicleData(ctx context.Context) ([]Train, error) {
-	resp, err := c.fetch(ctx, vehicleDataEndpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		XMLName xml.Name `xml:"TRAINS"`
-		Trains  []struct {
-			ID                     string `xml:"ID"`
-			Line                   string `xml:"TRAIN_LINE"`
-			Direction              string `xml:"DIRECTION"`
-			LastModified           string `xml:"LAST_MODIFIED"`
-			ScheduledDepartureTime string `xml:"SCHED_DEP_TIME"`
-			SecondsLate            int    `xml:"SEC_LATE"`
-			NextStop               string `xml:"NEXT_STOP"`
-			Longitude              string `xml:"LONGITUDE"`
-			Latitude               string `xml:"LATITUDE"`
-			TrackCircuit           string `xml:"ICS_TRACK_CKT"`
-		} `xml:"TRAIN"`
-	}{}
+	info := CallInfo{Method: "VehicleData", Endpoint: vehicleDataEndpoint}
+	return invoke(ctx, c, info, nil, c.vehicleData)
+}
 
-	err = xml.Unmarshal(resp, &data)
+func (c *Client) vehicleData(ctx context.Context) ([]Train, error) {
+	var data vehicleDataWire
+	resp, err := c.load(ctx, vehicleDataEndpoint, nil, &data)
 	if err != nil {
 		return nil, err
 	}
 
 	trains := make([]Train, 0, len(data.Trains))
 	for _, d := range data.Trains {
-		// Use a Regex to extract the numbers from the ID.
-		// Some trains have an "a" (amtrak suffix). Others
-		// randomly have leading or trailing ".".
-		//
-		// https://github.com/bamnet/njtapi/issues/7
-		idM := trainIDRe.FindStringSubmatch(d.ID)
-		if len(idM) < 2 {
-			continue
-		}
-		d.ID = idM[1]
-
-		id, err := strconv.Atoi(d.ID)
-		if err != nil {
-			return nil, err
-		}
+		// IDs carry operator suffixes and stray punctuation, which
+		// ParseTrainID separates from the train number.
+		id := ParseTrainID(d.ID)
 
 		latlng, err := parseLatLng(d.Latitude, d.Longitude)
 
@@ -242,12 +196,13 @@ func (c *Client) VehicleData(ctx context.Context) ([]Train, error) {
 
 		t := Train{
 			ID:           id,
-			Line:         d.Line,
+			Line:         resolveLine("", d.Line),
 			Direction:    d.Direction,
 			SecondsLate:  time.Duration(d.SecondsLate) * time.Second,
 			NextStop:     strings.TrimSpace(d.NextStop),
 			LatLng:       latlng,
 			TrackCircuit: strings.TrimSpace(d.TrackCircuit),
+			Stale:        resp.stale,
 		}
 		t.LastModified, err = c.parseTime(d.LastModified)
 		if err != nil {
@@ -262,20 +217,34 @@ func (c *Client) VehicleData(ctx context.Context) ([]Train, error) {
 			})
 		}
 		t.ParseErrors = parseErrs
+		c.logParseErrors(ctx, vehicleDataEndpoint, id.Raw, parseErrs)
 		trains = append(trains, t)
 	}
-	return removeDupTrains(trains), nil
+	return removeDupTrains(ctx, c.log(), trains), nil
 }
 
 // removeDupTrains ensures there is only 1 train per ID in the array.
+// IDs are compared in canonical form, so "6659" and "6659." are duplicates.
 // If duplicates are found, the train with the most recent LastModified time is kept.
-func removeDupTrains(trains []Train) []Train {
-	ts := map[int]Train{}
+// Each train removed is logged to log at debug level, since the API
+// routinely sends the same train more than once.
+func removeDupTrains(ctx context.Context, log *slog.Logger, trains []Train) []Train {
+	ts := map[string]Train{}
 
 	for _, t := range trains {
-		if val, ok := ts[t.ID]; !ok || val.LastModified.Before(t.LastModified) {
-			ts[t.ID] = t
+		key := t.ID.String()
+		val, ok := ts[key]
+		if !ok {
+			ts[key] = t
+			continue
+		}
+		dropped := t
+		if val.LastModified.Before(t.LastModified) {
+			ts[key], dropped = t, val
 		}
+		log.LogAttrs(ctx, slog.LevelDebug, "njtapi: removing duplicate train",
+			slog.String("train_id", key), slog.Time("kept_last_modified", ts[key].LastModified),
+			slog.Time("dropped_last_modified", dropped.LastModified))
 	}
 
 	if len(ts) == len(trains) {
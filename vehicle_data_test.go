@@ -13,7 +13,7 @@ import (
 )
 
 func TrainLess(t1 Train, t2 Train) bool {
-	return t1.ID < t2.ID
+	return t1.ID.String() < t2.ID.String()
 }
 
 func TestRemoveDupTrains(t *testing.T) {
@@ -24,27 +24,27 @@ func TestRemoveDupTrains(t *testing.T) {
 		want  []Train
 	}{
 		{
-			input: []Train{{ID: 1, LastModified: t1}},
-			want:  []Train{{ID: 1, LastModified: t1}},
+			input: []Train{{ID: NJTTrainID(1), LastModified: t1}},
+			want:  []Train{{ID: NJTTrainID(1), LastModified: t1}},
 		}, {
 			input: []Train{
-				{ID: 1, LastModified: t1},
-				{ID: 1, LastModified: t2},
+				{ID: NJTTrainID(1), LastModified: t1},
+				{ID: NJTTrainID(1), LastModified: t2},
 			},
-			want: []Train{{ID: 1, LastModified: t2}},
+			want: []Train{{ID: NJTTrainID(1), LastModified: t2}},
 		}, {
 			input: []Train{
-				{ID: 1, LastModified: t1},
-				{ID: 1, LastModified: t2},
-				{ID: 2, LastModified: t1},
+				{ID: NJTTrainID(1), LastModified: t1},
+				{ID: NJTTrainID(1), LastModified: t2},
+				{ID: NJTTrainID(2), LastModified: t1},
 			},
 			want: []Train{
-				{ID: 1, LastModified: t2},
-				{ID: 2, LastModified: t1},
+				{ID: NJTTrainID(1), LastModified: t2},
+				{ID: NJTTrainID(2), LastModified: t1},
 			},
 		},
 	} {
-		if got := removeDupTrains(r.input); !cmp.Equal(got, r.want, cmpopts.SortSlices(TrainLess)) {
+		if got := removeDupTrains(context.Background(), discardLogger, r.input); !cmp.Equal(got, r.want, cmpopts.SortSlices(TrainLess)) {
 			t.Errorf("removeDupTrains(%v) got %v want %v", r.input, got, r.want)
 		}
 	}
@@ -69,8 +69,8 @@ func TestVehicleData(t *testing.T) {
 
 	want := []Train{
 		{
-			ID:                     41,
-			Line:                   "Bergen County Line",
+			ID:                     NJTTrainID(41),
+			Line:                   lineBergenCounty,
 			Direction:              "Westbound",
 			LastModified:           time.Date(2019, 11, 18, 0, 0, 53, 0, loc),
 			ScheduledDepartureTime: time.Date(2019, 11, 19, 0, 40, 0, 0, loc),
@@ -78,8 +78,8 @@ func TestVehicleData(t *testing.T) {
 			LatLng:                 &LatLng{Lat: 40.7347, Lng: -74.0311},
 			TrackCircuit:           "",
 		}, {
-			ID:                     65,
-			Line:                   "Bergen County Line",
+			ID:                     NJTTrainID(65),
+			Line:                   lineBergenCounty,
 			Direction:              "Westbound",
 			LastModified:           time.Date(2019, 11, 18, 22, 01, 18, 0, loc),
 			ScheduledDepartureTime: time.Date(2019, 11, 18, 22, 8, 0, 0, loc),
@@ -88,8 +88,8 @@ func TestVehicleData(t *testing.T) {
 			LatLng:                 &LatLng{Lat: 41.374876, Lng: -74.694672},
 			TrackCircuit:           "OV-7611TK",
 		}, {
-			ID:                     6659,
-			Line:                   "Morris & Essex Line",
+			ID:                     TrainID{Raw: "6659.", Number: 6659, Operator: OperatorNJT},
+			Line:                   lineMorristown,
 			Direction:              "Westbound",
 			LastModified:           time.Date(2024, 06, 20, 21, 31, 52, 0, loc),
 			ScheduledDepartureTime: time.Date(2024, 06, 20, 19, 03, 45, 0, loc),
@@ -97,8 +97,8 @@ func TestVehicleData(t *testing.T) {
 			NextStop:               "",
 			TrackCircuit:           "EE-41UP",
 		}, {
-			ID:                     5193,
-			Line:                   "Raritan Valley Line",
+			ID:                     TrainID{Raw: ".5193", Number: 5193, Operator: OperatorNJT},
+			Line:                   lineRaritanValley,
 			Direction:              "Westbound",
 			LastModified:           time.Date(2024, 8, 28, 20, 54, 29, 0, loc),
 			ScheduledDepartureTime: time.Date(2024, 8, 28, 21, 52, 00, 0, loc),
@@ -148,8 +148,8 @@ func TestGetTrainMap(t *testing.T) {
 		{
 			trainID: 3874,
 			want: &Train{
-				ID:           3874,
-				Line:         "Northeast Corridor Line",
+				ID:           NJTTrainID(3874),
+				Line:         lineNortheastCorridor,
 				Direction:    "Eastbound",
 				LastModified: time.Date(2024, 05, 03, 20, 47, 01, 0, loc),
 				TrackCircuit: "AA-141UN",
@@ -159,8 +159,8 @@ func TestGetTrainMap(t *testing.T) {
 		{
 			trainID: 5152,
 			want: &Train{
-				ID:           5152,
-				Line:         "Raritan Valley Line",
+				ID:           NJTTrainID(5152),
+				Line:         lineRaritanValley,
 				Direction:    "Eastbound",
 				LastModified: time.Date(2024, 05, 03, 20, 49, 01, 0, loc),
 				TrackCircuit: "DK-B128TK",
@@ -173,7 +173,7 @@ func TestGetTrainMap(t *testing.T) {
 		},
 		{trainID: 999, want: nil, wantErr: ErrTrainNotFound},
 	} {
-		got, err := c.GetTrainMap(context.Background(), r.trainID)
+		got, err := c.GetTrainMap(context.Background(), NJTTrainID(r.trainID))
 		if err != r.wantErr {
 			t.Errorf("GetTrain(%d) unexpected error: %v", r.trainID, err)
 		}
@@ -215,54 +215,64 @@ func TestGetTrainStops(t *testing.T) {
 		{
 			trainID: 1085,
 			want: &Train{
-				ID:           1085,
+				ID:           NJTTrainID(1085),
 				LastModified: time.Date(2024, 07, 23, 20, 24, 35, 0, loc),
 				LatLng:       &LatLng{Lat: 40.9113, Lng: -74.2654},
 				Stops: []StationStop{
 					{
-						Name:          "Hoboken",
-						Departed:      true,
-						Time:          time.Date(2024, 07, 23, 19, 22, 00, 0, loc),
-						DepartureTime: time.Date(2024, 07, 23, 19, 22, 00, 0, loc),
-						Lines:         []Line{{"Bergen County Line"}, {"ME Line"}, {"North Jersey Coast Line"}},
-						Status:        "OnTime",
+						Name:            "Hoboken",
+						StationID:       "HB",
+						StationIDSource: StationIDFromFeed,
+						Departed:        true,
+						Time:            time.Date(2024, 07, 23, 19, 22, 00, 0, loc),
+						DepartureTime:   time.Date(2024, 07, 23, 19, 22, 00, 0, loc),
+						Lines:           []Line{lineBergenCounty, lineMorristown, lineNorthJerseyCoast},
+						Status:          "OnTime",
 					},
 					{
-						Name:          "Newark Broad Street",
-						Departed:      true,
-						Time:          time.Date(2024, 07, 23, 19, 39, 00, 0, loc),
-						DepartureTime: time.Date(2024, 07, 23, 19, 39, 00, 0, loc),
-						Lines:         []Line{{"Gladstone Branch"}, {"ME Line"}},
-						Status:        "OnTime",
+						Name:            "Newark Broad Street",
+						StationID:       "ND",
+						StationIDSource: StationIDFromFeed,
+						Departed:        true,
+						Time:            time.Date(2024, 07, 23, 19, 39, 00, 0, loc),
+						DepartureTime:   time.Date(2024, 07, 23, 19, 39, 00, 0, loc),
+						Lines:           []Line{lineGladstone, lineMorristown},
+						Status:          "OnTime",
 					},
 					{
-						Name:          "Watsessing Avenue",
-						Departed:      true,
-						Time:          time.Date(2024, 07, 23, 19, 47, 10, 0, loc),
-						DepartureTime: time.Date(2024, 07, 23, 19, 45, 30, 0, loc),
-						Status:        "OnTime",
+						Name:            "Watsessing Avenue",
+						StationID:       "WT",
+						StationIDSource: StationIDFromFeed,
+						Departed:        true,
+						Time:            time.Date(2024, 07, 23, 19, 47, 10, 0, loc),
+						DepartureTime:   time.Date(2024, 07, 23, 19, 45, 30, 0, loc),
+						Status:          "OnTime",
 					},
 					{
-						Name:          "Mountain View",
-						Departed:      false,
-						Time:          time.Date(2024, 07, 23, 20, 24, 22, 0, loc),
-						DepartureTime: time.Date(2024, 07, 23, 20, 23, 0, 0, loc),
-						Status:        "OnTime",
+						Name:            "Mountain View",
+						StationID:       "MV",
+						StationIDSource: StationIDFromFeed,
+						Departed:        false,
+						Time:            time.Date(2024, 07, 23, 20, 24, 22, 0, loc),
+						DepartureTime:   time.Date(2024, 07, 23, 20, 23, 0, 0, loc),
+						Status:          "OnTime",
 					},
 					{
-						Name:          "Hackettstown",
-						Departed:      false,
-						Time:          time.Date(2024, 07, 23, 21, 26, 0, 0, loc),
-						DepartureTime: time.Date(2024, 07, 23, 21, 26, 0, 0, loc),
-						Lines:         []Line{{"ME Line"}},
-						Status:        "Delayed",
+						Name:            "Hackettstown",
+						StationID:       "HQ",
+						StationIDSource: StationIDFromFeed,
+						Departed:        false,
+						Time:            time.Date(2024, 07, 23, 21, 26, 0, 0, loc),
+						DepartureTime:   time.Date(2024, 07, 23, 21, 26, 0, 0, loc),
+						Lines:           []Line{lineMorristown},
+						Status:          "Delayed",
 					},
 				},
 			},
 			wantErr: nil,
 		},
 	} {
-		got, err := c.GetTrainStops(context.Background(), r.trainID)
+		got, err := c.GetTrainStops(context.Background(), NJTTrainID(r.trainID))
 		if err != r.wantErr {
 			t.Errorf("GetTrain(%d) unexpected error: %v", r.trainID, err)
 		}
@@ -272,6 +282,63 @@ func TestGetTrainStops(t *testing.T) {
 	}
 }
 
+func TestGetTrainMapAmtrak(t *testing.T) {
+	var trainIDs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trainIDs = append(trainIDs, r.URL.Query().Get("trainID"))
+		_, _ = w.Write([]byte(`<Trains><Train><Train_ID>A137</Train_ID><TrainLine>Amtrak</TrainLine><DIRECTION>Westbound</DIRECTION></Train></Trains>`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	train, err := c.GetTrainMap(context.Background(), ParseTrainID("A137"))
+	if err != nil {
+		t.Fatalf("GetTrainMap() error: %v", err)
+	}
+	if want := (TrainID{Raw: "A137", Number: 137, Operator: OperatorAmtrak}); train.ID != want {
+		t.Errorf("GetTrainMap() ID got %+v want %+v", train.ID, want)
+	}
+	if diff := cmp.Diff([]string{"A137"}, trainIDs); diff != "" {
+		t.Errorf("trainID params mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetTrainMapCanonicalID(t *testing.T) {
+	var trainIDs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trainIDs = append(trainIDs, r.URL.Query().Get("trainID"))
+		_, _ = w.Write([]byte(`<Trains><Train><Train_ID>6659</Train_ID><DIRECTION>Eastbound</DIRECTION></Train></Trains>`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	for _, id := range []string{"6659.", "TBD"} {
+		if _, err := c.GetTrainMap(context.Background(), ParseTrainID(id)); err != nil {
+			t.Fatalf("GetTrainMap(%q) error: %v", id, err)
+		}
+	}
+	// Stray punctuation is dropped; unrecognized IDs are sent as is.
+	if diff := cmp.Diff([]string{"6659", "TBD"}, trainIDs); diff != "" {
+		t.Errorf("trainID params mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestVehicleDataUnrecognizedID(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<TRAINS><TRAIN><ID>TBD</ID><NEXT_STOP>Dover</NEXT_STOP></TRAIN></TRAINS>`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	trains, err := c.VehicleData(context.Background())
+	if err != nil {
+		t.Fatalf("VehicleData() error: %v", err)
+	}
+	if len(trains) != 1 || trains[0].ID != (TrainID{Raw: "TBD", Operator: OperatorOther}) || trains[0].NextStop != "Dover" {
+		t.Errorf("VehicleData() got %+v want the TBD train", trains)
+	}
+}
+
 func TestVehicleDataParseErrors(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
@@ -331,7 +398,7 @@ func TestGetTrainMapParseErrors(t *testing.T) {
 	defer ts.Close()
 
 	c := NewClient(ts.URL, "username", "pa$$word")
-	train, err := c.GetTrainMap(context.Background(), 1234)
+	train, err := c.GetTrainMap(context.Background(), NJTTrainID(1234))
 	if err != nil {
 		t.Fatalf("GetTrainMap() error: %v", err)
 	}
@@ -363,7 +430,7 @@ func TestGetTrainStopsParseErrors(t *testing.T) {
 	defer ts.Close()
 
 	c := NewClient(ts.URL, "username", "pa$$word")
-	train, err := c.GetTrainStops(context.Background(), 1085)
+	train, err := c.GetTrainStops(context.Background(), NJTTrainID(1085))
 	if err != nil {
 		t.Fatalf("GetTrainStops() error: %v", err)
 	}
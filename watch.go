@@ -0,0 +1,218 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// defaultWatchInterval is used by WatchTrain when no interval is given.
+const defaultWatchInterval = 30 * time.Second
+
+// TrainEventKind is the kind of change reported by WatchTrain.
+type TrainEventKind int
+
+// Changes reported by WatchTrain.
+const (
+	TrainMoved           TrainEventKind = iota + 1 // The train's position changed
+	TrainDelayChanged                              // SecondsLate changed
+	TrainNextStopChanged                           // NextStop changed
+	TrainStopDeparted                              // The train departed a stop
+	TrainDisappeared                               // The train is no longer reported by any endpoint
+)
+
+func (k TrainEventKind) String() string {
+	switch k {
+	case TrainMoved:
+		return "moved"
+	case TrainDelayChanged:
+		return "delay changed"
+	case TrainNextStopChanged:
+		return "next stop changed"
+	case TrainStopDeparted:
+		return "stop departed"
+	case TrainDisappeared:
+		return "disappeared"
+	}
+	return "unknown"
+}
+
+// A TrainEvent is a single change to a watched train.
+type TrainEvent struct {
+	Kind TrainEventKind
+	Stop *StationStop // Stop departed, for TrainStopDeparted
+}
+
+// A TrainUpdate is sent by WatchTrain when a watched train changes, or when
+// polling it fails.
+type TrainUpdate struct {
+	Train    *MergedTrain // Latest snapshot, or nil if the train disappeared or Err is set
+	Previous *MergedTrain // Snapshot before this update, or nil for the first one
+	Events   []TrainEvent // Changes since Previous
+	Err      error        // Error from polling; the watch keeps going after backing off
+}
+
+// WatchTrain polls GetTrain every interval and sends an update on the
+// returned channel whenever the train changes. The first update carries
+// the train's initial state and no events; snapshots are skipped unless the
+// LastModified reported by one of GetTrain's sources has changed.
+//
+// Failed polls are reported as updates with Err set, and polling backs off
+// until the API recovers. If only some of GetTrain's sources fail, the
+// fields last taken from the failed ones are carried forward from the
+// previous snapshot, so they are not reported as changes while the others
+// still are. A train that has not been seen yet is polled quietly until it
+// appears. The channel is closed once ctx is done.
+func (c *Client) WatchTrain(ctx context.Context, trainID TrainID, interval time.Duration) <-chan TrainUpdate {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	updates := make(chan TrainUpdate)
+	go func() {
+		defer close(updates)
+		backoff := &RetryPolicy{InitialBackoff: interval, MaxBackoff: 8 * interval, Multiplier: 2, Jitter: 0.2}
+		var prev *MergedTrain
+		failures := 0
+		for {
+			wait := interval
+			train, err := c.GetTrain(ctx, trainID)
+			if err == nil {
+				train = carryForward(prev, train)
+			}
+			var update *TrainUpdate
+			switch {
+			case ctx.Err() != nil:
+				return
+			case errors.Is(err, ErrTrainNotFound):
+				failures = 0
+				if prev != nil {
+					update = &TrainUpdate{Previous: prev, Events: []TrainEvent{{Kind: TrainDisappeared}}}
+					prev = nil
+				}
+			case err != nil:
+				failures++
+				wait = backoff.backoff(failures)
+				update = &TrainUpdate{Previous: prev, Err: err}
+			default:
+				failures = 0
+				if prev == nil || !sameSnapshot(prev, train) {
+					if events := trainEvents(prev, train); prev == nil || len(events) > 0 {
+						update = &TrainUpdate{Train: train, Previous: prev, Events: events}
+					}
+					prev = train
+				}
+			}
+
+			if update != nil {
+				select {
+				case updates <- *update:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+	}()
+	return updates
+}
+
+// carryForward returns cur with every field which prev took from a source
+// that failed for cur copied from prev.
+func carryForward(prev, cur *MergedTrain) *MergedTrain {
+	if prev == nil || len(cur.Errors) == 0 {
+		return cur
+	}
+	m := *cur
+	failed := func(f FieldSource) bool { return cur.Errors[f.Source] != nil }
+	if failed(prev.Sources.Line) {
+		m.Line, m.Sources.Line = prev.Line, prev.Sources.Line
+	}
+	if failed(prev.Sources.Direction) {
+		m.Direction, m.Sources.Direction = prev.Direction, prev.Sources.Direction
+	}
+	if failed(prev.Sources.LastModified) {
+		m.LastModified, m.Sources.LastModified = prev.LastModified, prev.Sources.LastModified
+	}
+	if failed(prev.Sources.ScheduledDepartureTime) {
+		m.ScheduledDepartureTime, m.Sources.ScheduledDepartureTime = prev.ScheduledDepartureTime, prev.Sources.ScheduledDepartureTime
+	}
+	if failed(prev.Sources.SecondsLate) {
+		m.SecondsLate, m.Sources.SecondsLate = prev.SecondsLate, prev.Sources.SecondsLate
+	}
+	if failed(prev.Sources.NextStop) {
+		m.NextStop, m.Sources.NextStop = prev.NextStop, prev.Sources.NextStop
+	}
+	if failed(prev.Sources.LatLng) {
+		m.LatLng, m.Sources.LatLng = prev.LatLng, prev.Sources.LatLng
+	}
+	if failed(prev.Sources.TrackCircuit) {
+		m.TrackCircuit, m.Sources.TrackCircuit = prev.TrackCircuit, prev.Sources.TrackCircuit
+	}
+	if failed(prev.Sources.Stops) {
+		m.Stops, m.Sources.Stops = prev.Stops, prev.Sources.Stops
+	}
+	return &m
+}
+
+// sameSnapshot reports whether every source of two snapshots reported the
+// same LastModified, so they cannot differ.
+func sameSnapshot(a, b *MergedTrain) bool {
+	ta, tb := sourceTimes(a), sourceTimes(b)
+	if len(ta) == 0 || len(ta) != len(tb) {
+		return false
+	}
+	for src, t := range ta {
+		if t.IsZero() || !t.Equal(tb[src]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceTimes returns the LastModified reported by each source of a train.
+func sourceTimes(m *MergedTrain) map[TrainSource]time.Time {
+	times := map[TrainSource]time.Time{}
+	for _, f := range []FieldSource{
+		m.Sources.Line, m.Sources.Direction, m.Sources.LastModified, m.Sources.ScheduledDepartureTime,
+		m.Sources.SecondsLate, m.Sources.NextStop, m.Sources.LatLng, m.Sources.TrackCircuit, m.Sources.Stops,
+	} {
+		if f.Source != SourceNone {
+			times[f.Source] = f.LastModified
+		}
+	}
+	return times
+}
+
+// trainEvents lists the changes between two snapshots of a train.
+func trainEvents(prev, cur *MergedTrain) []TrainEvent {
+	if prev == nil {
+		return nil
+	}
+	var events []TrainEvent
+	if cur.LatLng != nil && (prev.LatLng == nil || *cur.LatLng != *prev.LatLng) {
+		events = append(events, TrainEvent{Kind: TrainMoved})
+	}
+	// Only the vehicle data reports delays, and it drops trains it no longer
+	// considers active, so a missing delay is not a change.
+	if cur.Sources.SecondsLate.Source == SourceVehicleData && prev.Sources.SecondsLate.Source == SourceVehicleData &&
+		cur.SecondsLate != prev.SecondsLate {
+		events = append(events, TrainEvent{Kind: TrainDelayChanged})
+	}
+	if cur.NextStop != "" && cur.NextStop != prev.NextStop {
+		events = append(events, TrainEvent{Kind: TrainNextStopChanged})
+	}
+	for i := range cur.Stops {
+		s := &cur.Stops[i]
+		if s.Departed && i < len(prev.Stops) && prev.Stops[i].Name == s.Name && !prev.Stops[i].Departed {
+			events = append(events, TrainEvent{Kind: TrainStopDeparted, Stop: s})
+		}
+	}
+	return events
+}
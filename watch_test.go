@@ -0,0 +1,202 @@
+package njtapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bamnet/njtapi/njtapitest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestTrainEvents(t *testing.T) {
+	stops := func(departed ...bool) []StationStop {
+		s := []StationStop{{Name: "New York Penn Station"}, {Name: "Secaucus Upper Lvl"}}
+		for i, d := range departed {
+			s[i].Departed = d
+		}
+		return s
+	}
+	base := MergedTrain{
+		Train:   Train{LatLng: &LatLng{Lat: 1, Lng: 1}, NextStop: "Secaucus Upper Lvl", Stops: stops(true, false), SecondsLate: time.Minute},
+		Sources: TrainSources{SecondsLate: FieldSource{Source: SourceVehicleData}},
+	}
+
+	for _, r := range []struct {
+		name   string
+		change func(*MergedTrain)
+		want   []TrainEventKind
+	}{
+		{"unchanged", func(*MergedTrain) {}, nil},
+		{"moved", func(m *MergedTrain) { m.LatLng = &LatLng{Lat: 2, Lng: 1} }, []TrainEventKind{TrainMoved}},
+		{"position lost", func(m *MergedTrain) { m.LatLng = nil }, nil},
+		{"delayed", func(m *MergedTrain) { m.SecondsLate = 2 * time.Minute }, []TrainEventKind{TrainDelayChanged}},
+		{"on time", func(m *MergedTrain) { m.SecondsLate = 0 }, []TrainEventKind{TrainDelayChanged}},
+		{"left vehicle data", func(m *MergedTrain) {
+			m.SecondsLate, m.NextStop, m.Sources.SecondsLate = 0, "", FieldSource{}
+		}, nil},
+		{"next stop", func(m *MergedTrain) { m.NextStop = "Newark Penn Station" }, []TrainEventKind{TrainNextStopChanged}},
+		{"departed", func(m *MergedTrain) { m.Stops = stops(true, true) }, []TrainEventKind{TrainStopDeparted}},
+	} {
+		cur := base
+		r.change(&cur)
+		var got []TrainEventKind
+		for _, e := range trainEvents(&base, &cur) {
+			got = append(got, e.Kind)
+			if e.Kind == TrainStopDeparted && e.Stop.Name != "Secaucus Upper Lvl" {
+				t.Errorf("%s: departed stop got %q", r.name, e.Stop.Name)
+			}
+		}
+		if diff := cmp.Diff(r.want, got); diff != "" {
+			t.Errorf("%s: trainEvents() mismatch (-want +got):\n%s", r.name, diff)
+		}
+	}
+
+	if got := trainEvents(nil, &base); got != nil {
+		t.Errorf("trainEvents(nil) got %v want none", got)
+	}
+}
+
+func TestWatchTrain(t *testing.T) {
+	srv := njtapitest.NewServer()
+	defer srv.Close()
+
+	departs := time.Date(2024, 5, 3, 20, 7, 0, 0, time.UTC)
+	ny := njtapitest.NewStation("NY", "New York Penn Station")
+	se := njtapitest.NewStation("SE", "Secaucus Upper Lvl")
+	np := njtapitest.NewStation("NP", "Newark Penn Station")
+	srv.AddStations(ny, se, np)
+	newTrain := func(lat float64, delay time.Duration, departedThrough string) *njtapitest.Train {
+		train := njtapitest.NewTrain("3883", "Northeast Corridor Line", "NEC").
+			At(lat, -74.0403).
+			Stop(ny, departs).Stop(se, departs.Add(10*time.Minute)).Stop(np, departs.Add(20*time.Minute)).
+			DepartedThrough(departedThrough)
+		train.Direction = "Westbound"
+		train.Delay = delay
+		train.LastModified = departs.Add(time.Duration(lat*1000) * time.Second)
+		return train
+	}
+	srv.AddTrains(newTrain(40.75, 0, "NY"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewClient(srv.URL, njtapitest.Username, njtapitest.Password)
+	updates := c.WatchTrain(ctx, NJTTrainID(3883), time.Millisecond)
+	next := func() TrainUpdate {
+		t.Helper()
+		select {
+		case u, ok := <-updates:
+			if !ok {
+				t.Fatal("updates closed unexpectedly")
+			}
+			return u
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an update")
+		}
+		return TrainUpdate{}
+	}
+	kinds := func(u TrainUpdate) []TrainEventKind {
+		var k []TrainEventKind
+		for _, e := range u.Events {
+			k = append(k, e.Kind)
+		}
+		return k
+	}
+
+	first := next()
+	if first.Err != nil || first.Train == nil || first.Previous != nil || len(first.Events) != 0 {
+		t.Fatalf("first update got %+v want the initial state", first)
+	}
+	if first.Train.NextStop != "Secaucus Upper Lvl" {
+		t.Errorf("first update NextStop got %q", first.Train.NextStop)
+	}
+
+	srv.AddTrains(newTrain(40.76, 2*time.Minute, "SE"))
+	// A poll racing the change may see it from some endpoints only, which
+	// splits its events over two updates.
+	var got []TrainEventKind
+	u := next()
+	for got = kinds(u); len(got) < 4 && u.Err == nil; {
+		u = next()
+		got = append(got, kinds(u)...)
+	}
+	want := []TrainEventKind{TrainMoved, TrainDelayChanged, TrainNextStopChanged, TrainStopDeparted}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b TrainEventKind) bool { return a < b })); diff != "" {
+		t.Errorf("update events mismatch (-want +got):\n%s", diff)
+	}
+	if u.Previous == nil || u.Train == nil || u.Train.SecondsLate != 2*time.Minute {
+		t.Errorf("update got Previous %+v, Train %+v", u.Previous, u.Train)
+	}
+
+	// With the stop list failing, moves are still reported and the stops
+	// are carried forward rather than reported as changed.
+	stops := u.Train.Stops
+	srv.InjectFault(njtapitest.TrainStopsEndpoint, njtapitest.Fault{StatusCode: http.StatusInternalServerError})
+	srv.AddTrains(newTrain(40.77, 2*time.Minute, "SE"))
+	u = next()
+	if diff := cmp.Diff([]TrainEventKind{TrainMoved}, kinds(u)); diff != "" || u.Err != nil {
+		t.Errorf("update with the stop list failing got %+v, events mismatch (-want +got):\n%s", u, diff)
+	}
+	if u.Train == nil || u.Train.Errors[SourceTrainStops] == nil || !cmp.Equal(stops, u.Train.Stops) {
+		t.Errorf("update with the stop list failing got %+v want the previous stops and the error", u.Train)
+	}
+
+	// Trains drop out of the vehicle data when it no longer considers them
+	// active, which is not a change in delay.
+	srv.InjectFault(njtapitest.VehicleDataEndpoint, njtapitest.Fault{StatusCode: http.StatusOK, Body: "<TRAINS></TRAINS>"})
+	srv.AddTrains(newTrain(40.78, 2*time.Minute, "SE"))
+	u = next()
+	if diff := cmp.Diff([]TrainEventKind{TrainMoved}, kinds(u)); diff != "" || u.Err != nil {
+		t.Errorf("update with the train missing from the vehicle data got %+v, events mismatch (-want +got):\n%s", u, diff)
+	}
+
+	var apiErr *APIError
+
+	srv.InjectFault("", njtapitest.Fault{StatusCode: http.StatusServiceUnavailable})
+	u = next()
+	if !errors.As(u.Err, &apiErr) || u.Train != nil {
+		t.Errorf("update during outage got %+v want an *APIError", u)
+	}
+	srv.ClearFaults()
+
+	srv.RemoveTrain("3883")
+	for u = next(); u.Err != nil; u = next() {
+		// Drain errors from polls made before the faults were cleared.
+	}
+	if diff := cmp.Diff([]TrainEventKind{TrainDisappeared}, kinds(u)); diff != "" || u.Train != nil {
+		t.Errorf("disappearance events mismatch (-want +got):\n%s", diff)
+	}
+
+	cancel()
+	for range updates {
+		// Drain until the watcher notices the cancellation.
+	}
+}
+
+func TestSameSnapshot(t *testing.T) {
+	t1 := time.Date(2024, 5, 3, 20, 7, 0, 0, time.UTC)
+	snapshot := func(mapTime, stopsTime time.Time) *MergedTrain {
+		return &MergedTrain{Sources: TrainSources{
+			Line:  FieldSource{Source: SourceTrainMap, LastModified: mapTime},
+			Stops: FieldSource{Source: SourceTrainStops, LastModified: stopsTime},
+		}}
+	}
+	for _, r := range []struct {
+		name string
+		a, b *MergedTrain
+		want bool
+	}{
+		{"same", snapshot(t1, t1), snapshot(t1, t1), true},
+		{"stops updated", snapshot(t1, t1), snapshot(t1, t1.Add(time.Second)), false},
+		{"no times", snapshot(time.Time{}, time.Time{}), snapshot(time.Time{}, time.Time{}), false},
+		{"source lost", snapshot(t1, t1), &MergedTrain{Sources: TrainSources{Line: FieldSource{Source: SourceTrainMap, LastModified: t1}}}, false},
+		{"no sources", &MergedTrain{}, &MergedTrain{}, false},
+	} {
+		if got := sameSnapshot(r.a, r.b); got != r.want {
+			t.Errorf("%s: sameSnapshot() got %v want %v", r.name, got, r.want)
+		}
+	}
+}
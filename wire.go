@@ -0,0 +1,211 @@
+package njtapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Format selects how API responses are decoded.
+type Format int
+
+const (
+	// FormatAuto picks XML or JSON based on the response's Content-Type,
+	// falling back to sniffing the body. This is the default.
+	FormatAuto Format = iota
+	// FormatXML always decodes responses as XML.
+	FormatXML
+	// FormatJSON always decodes responses as JSON.
+	FormatJSON
+)
+
+// decode unmarshals an API response into one of the wire structs below.
+func (c *Client) decode(resp *response, v any) error {
+	if c.isJSON(resp) {
+		return json.Unmarshal(resp.body, v)
+	}
+	return xml.Unmarshal(resp.body, v)
+}
+
+// isJSON reports whether resp should be decoded as JSON.
+func (c *Client) isJSON(resp *response) bool {
+	switch c.format {
+	case FormatXML:
+		return false
+	case FormatJSON:
+		return true
+	}
+	if mt, _, err := mime.ParseMediaType(resp.contentType); err == nil {
+		switch {
+		case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+			return true
+		case strings.HasSuffix(mt, "/xml") || strings.HasSuffix(mt, "+xml"):
+			return false
+		}
+	}
+	trimmed := bytes.TrimSpace(resp.body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// flexInt is an int which the JSON API may send as either a number or a string.
+type flexInt int
+
+func (i *flexInt) UnmarshalJSON(b []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		n = json.Number(strings.TrimSpace(s))
+	}
+	if n == "" {
+		*i = 0
+		return nil
+	}
+	v, err := strconv.Atoi(string(n))
+	if err != nil {
+		return err
+	}
+	*i = flexInt(v)
+	return nil
+}
+
+// The wire structs below mirror the API payloads. The XML and JSON variants
+// share field names, but the JSON API returns bare arrays where the XML
+// wraps lists in a container element.
+
+// stationDataWire is the payload of the train schedule endpoint.
+type stationDataWire struct {
+	XMLName      xml.Name              `xml:"STATION" json:"-"`
+	Station2Char string                `xml:"STATION_2CHAR" json:"STATION_2CHAR"`
+	StationName  string                `xml:"STATIONNAME" json:"STATIONNAME"`
+	Banners      []bannerMsgWire       `xml:"BANNERMSGS>BANNERMSG" json:"BANNERMSGS"`
+	Items        []stationDataItemWire `xml:"ITEMS>ITEM" json:"ITEMS"`
+}
+
+type bannerMsgWire struct {
+	ID    string `xml:"MSG_ID" json:"MSG_ID"`
+	Type  string `xml:"MSG_TYPE" json:"MSG_TYPE"`
+	Text  string `xml:"MSG_TEXT" json:"MSG_TEXT"`
+	Start string `xml:"MSG_PUBDATE" json:"MSG_PUBDATE"`
+	End   string `xml:"MSG_EXPDATE" json:"MSG_EXPDATE"`
+}
+
+type stationDataItemWire struct {
+	Index                  flexInt               `xml:"ITEM_INDEX" json:"ITEM_INDEX"`
+	ScheduledDepartureDate string                `xml:"SCHED_DEP_DATE" json:"SCHED_DEP_DATE"`
+	Destination            string                `xml:"DESTINATION" json:"DESTINATION"`
+	Track                  string                `xml:"TRACK" json:"TRACK"`
+	Line                   string                `xml:"LINE" json:"LINE"`
+	TrainID                string                `xml:"TRAIN_ID" json:"TRAIN_ID"`
+	ConnectingTrainID      string                `xml:"CONNECTING_TRAIN_ID" json:"CONNECTING_TRAIN_ID"`
+	Status                 string                `xml:"STATUS" json:"STATUS"`
+	SecondsLate            flexInt               `xml:"SEC_LATE" json:"SEC_LATE"`
+	LastModified           string                `xml:"LAST_MODIFIED" json:"LAST_MODIFIED"`
+	BackColor              string                `xml:"BACKCOLOR" json:"BACKCOLOR"`
+	ForeColor              string                `xml:"FORECOLOR" json:"FORECOLOR"`
+	ShadowColor            string                `xml:"SHADOWCOLOR" json:"SHADOWCOLOR"`
+	GPSTime                string                `xml:"GPSTIME" json:"GPSTIME"`
+	LineAbbreviation       string                `xml:"LINEABBREVIATION" json:"LINEABBREVIATION"`
+	InlineMsg              string                `xml:"INLINEMSG" json:"INLINEMSG"`
+	Longitude              string                `xml:"GPSLONGITUDE" json:"GPSLONGITUDE"`
+	Latitude               string                `xml:"GPSLATITUDE" json:"GPSLATITUDE"`
+	StationPosition        flexInt               `xml:"STATION_POSITION" json:"STATION_POSITION"`
+	Stops                  []stationDataStopWire `xml:"STOPS>STOP" json:"STOPS"`
+}
+
+type stationDataStopWire struct {
+	Name     string `xml:"NAME" json:"NAME"`
+	Time     string `xml:"TIME" json:"TIME"`
+	Departed string `xml:"DEPARTED" json:"DEPARTED"`
+	Status   string `xml:"STOP_STATUS" json:"STOP_STATUS"`
+}
+
+// stationListWire is the payload of the station list endpoint.
+type stationListWire struct {
+	XMLName  xml.Name              `xml:"STATIONS"`
+	Stations []stationListItemWire `xml:"STATION"`
+}
+
+type stationListItemWire struct {
+	Name         string `xml:"STATIONNAME" json:"STATIONNAME"`
+	Station2Char string `xml:"STATION_2CHAR" json:"STATION_2CHAR"`
+}
+
+func (w *stationListWire) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &w.Stations)
+}
+
+// trainMapWire is the payload of the train map endpoint.
+type trainMapWire struct {
+	XMLName xml.Name            `xml:"Trains"`
+	Trains  []trainMapTrainWire `xml:"Train"`
+}
+
+type trainMapTrainWire struct {
+	ID           string `xml:"Train_ID" json:"Train_ID"`
+	Line         string `xml:"TrainLine" json:"TrainLine"`
+	Direction    string `xml:"DIRECTION" json:"DIRECTION"`
+	LastModified string `xml:"LAST_MODIFIED" json:"LAST_MODIFIED"`
+	Longitude    string `xml:"longitude" json:"longitude"`
+	Latitude     string `xml:"latitude" json:"latitude"`
+	TrackCircuit string `xml:"TrackCKT" json:"TrackCKT"`
+}
+
+func (w *trainMapWire) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &w.Trains)
+}
+
+// trainStopsWire is the payload of the train stop list endpoint.
+type trainStopsWire struct {
+	XMLName     xml.Name             `xml:"Train" json:"-"`
+	ID          string               `xml:"Train_ID" json:"Train_ID"`
+	Destination string               `xml:"DESTINATION" json:"DESTINATION"`
+	GPSTime     string               `xml:"GPSTIME" json:"GPSTIME"`
+	Longitude   string               `xml:"GPSLONGITUDE" json:"GPSLONGITUDE"`
+	Latitude    string               `xml:"GPSLATITUDE" json:"GPSLATITUDE"`
+	Stops       []trainStopsStopWire `xml:"STOPS>STOP" json:"STOPS"`
+}
+
+type trainStopsStopWire struct {
+	Name          string               `xml:"NAME" json:"NAME"`
+	Station2Char  string               `xml:"STATION_2CHAR" json:"STATION_2CHAR"`
+	Time          string               `xml:"TIME" json:"TIME"`
+	Departed      string               `xml:"DEPARTED" json:"DEPARTED"`
+	Status        string               `xml:"STOP_STATUS" json:"STOP_STATUS"`
+	DepartureTime string               `xml:"DEP_TIME" json:"DEP_TIME"`
+	Lines         []trainStopsLineWire `xml:"STOP_LINES>STOP_LINE" json:"STOP_LINES"`
+}
+
+type trainStopsLineWire struct {
+	Code  string `xml:"LINE_CODE" json:"LINE_CODE"`
+	Name  string `xml:"LINE_NAME" json:"LINE_NAME"`
+	Color string `xml:"LINE_COLOR" json:"LINE_COLOR"`
+}
+
+// vehicleDataWire is the payload of the vehicle data endpoint.
+type vehicleDataWire struct {
+	XMLName xml.Name               `xml:"TRAINS"`
+	Trains  []vehicleDataTrainWire `xml:"TRAIN"`
+}
+
+type vehicleDataTrainWire struct {
+	ID                     string  `xml:"ID" json:"ID"`
+	Line                   string  `xml:"TRAIN_LINE" json:"TRAIN_LINE"`
+	Direction              string  `xml:"DIRECTION" json:"DIRECTION"`
+	LastModified           string  `xml:"LAST_MODIFIED" json:"LAST_MODIFIED"`
+	ScheduledDepartureTime string  `xml:"SCHED_DEP_TIME" json:"SCHED_DEP_TIME"`
+	SecondsLate            flexInt `xml:"SEC_LATE" json:"SEC_LATE"`
+	NextStop               string  `xml:"NEXT_STOP" json:"NEXT_STOP"`
+	Longitude              string  `xml:"LONGITUDE" json:"LONGITUDE"`
+	Latitude               string  `xml:"LATITUDE" json:"LATITUDE"`
+	TrackCircuit           string  `xml:"ICS_TRACK_CKT" json:"ICS_TRACK_CKT"`
+}
+
+func (w *vehicleDataWire) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &w.Trains)
+}
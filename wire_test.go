@@ -0,0 +1,178 @@
+package njtapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newFixtureServer serves testdata/<name>.<ext>, where name is picked by
+// route from the request and ext is fixed for the server.
+func newFixtureServer(t *testing.T, ext string, route func(*http.Request) string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/"+route(r)+"."+ext)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestGoldenFormats checks every endpoint produces identical results from
+// the XML and JSON variants of the same payload.
+func TestGoldenFormats(t *testing.T) {
+	for _, r := range []struct {
+		name  string
+		route func(*http.Request) string
+		call  func(*Client) (any, error)
+	}{
+		{
+			name:  "StationList",
+			route: func(*http.Request) string { return "getStationList" },
+			call:  func(c *Client) (any, error) { return c.StationList(context.Background()) },
+		}, {
+			name:  "StationData/SE",
+			route: func(*http.Request) string { return "getTrainSchedule1" },
+			call:  func(c *Client) (any, error) { return c.StationData(context.Background(), "SE") },
+		}, {
+			name:  "StationData/NY",
+			route: func(*http.Request) string { return "getTrainSchedule2" },
+			call:  func(c *Client) (any, error) { return c.StationData(context.Background(), "NY") },
+		}, {
+			name:  "StationData/HB",
+			route: func(*http.Request) string { return "getTrainSchedule3" },
+			call:  func(c *Client) (any, error) { return c.StationData(context.Background(), "HB") },
+		}, {
+			name:  "GetTrainMap/3874",
+			route: func(*http.Request) string { return "getTrainMap1" },
+			call:  func(c *Client) (any, error) { return c.GetTrainMap(context.Background(), NJTTrainID(3874)) },
+		}, {
+			name:  "GetTrainMap/5152",
+			route: func(*http.Request) string { return "getTrainMap2" },
+			call:  func(c *Client) (any, error) { return c.GetTrainMap(context.Background(), NJTTrainID(5152)) },
+		}, {
+			name:  "GetTrainMap/missing",
+			route: func(*http.Request) string { return "getTrainMapMissing" },
+			call:  func(c *Client) (any, error) { return c.GetTrainMap(context.Background(), NJTTrainID(999)) },
+		}, {
+			name:  "GetTrainStops",
+			route: func(*http.Request) string { return "getTrainStopList1" },
+			call:  func(c *Client) (any, error) { return c.GetTrainStops(context.Background(), NJTTrainID(1085)) },
+		}, {
+			name:  "VehicleData",
+			route: func(*http.Request) string { return "getVehicleData" },
+			call:  func(c *Client) (any, error) { return c.VehicleData(context.Background()) },
+		},
+	} {
+		xmlSrv := newFixtureServer(t, "xml", r.route)
+		jsonSrv := newFixtureServer(t, "json", r.route)
+
+		wantXML, errXML := r.call(NewClient(xmlSrv.URL, "username", "pa$$word"))
+		gotJSON, errJSON := r.call(NewClient(jsonSrv.URL, "username", "pa$$word"))
+		if errXML != errJSON {
+			t.Errorf("%s: XML error %v, JSON error %v", r.name, errXML, errJSON)
+		}
+		if diff := cmp.Diff(wantXML, gotJSON); diff != "" {
+			t.Errorf("%s: XML and JSON mismatch (-xml +json):\n%s", r.name, diff)
+		}
+	}
+}
+
+func TestFormatOverride(t *testing.T) {
+	// A JSON payload mislabeled as XML.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(`[{"STATION_2CHAR":"NY","STATIONNAME":"New York"}]`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	if _, err := c.StationList(context.Background()); err == nil {
+		t.Error("StationList() expected error decoding JSON as XML, got none")
+	}
+
+	c.SetFormat(FormatJSON)
+	got, err := c.StationList(context.Background())
+	if err != nil {
+		t.Fatalf("StationList() unexpected error: %v", err)
+	}
+	want := []Station{{ID: "NY", Name: "New York", Aliases: []string{"New York Penn Station", "New York Penn"}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("StationList() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIsJSON(t *testing.T) {
+	c := &Client{}
+	for _, r := range []struct {
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"application/json; charset=utf-8", "", true},
+		{"application/problem+json", "", true},
+		{"text/xml; charset=utf-8", "[]", false},
+		{"application/xml", "", false},
+		{"", " \n[{}]", true},
+		{"", `{"a":1}`, true},
+		{"text/plain", `<?xml version="1.0"?>`, false},
+		{"", "", false},
+	} {
+		resp := &response{body: []byte(r.body), contentType: r.contentType}
+		if got := c.isJSON(resp); got != r.want {
+			t.Errorf("isJSON(%q, %q) got %v want %v", r.contentType, r.body, got, r.want)
+		}
+	}
+}
+
+func TestFlexInt(t *testing.T) {
+	for _, r := range []struct {
+		input   string
+		want    flexInt
+		wantErr bool
+	}{
+		{`240`, 240, false},
+		{`"-60"`, -60, false},
+		{`" 8700 "`, 8700, false},
+		{`""`, 0, false},
+		{`"soon"`, 0, true},
+		{`1.5`, 0, true},
+	} {
+		var got flexInt
+		err := json.Unmarshal([]byte(r.input), &got)
+		if (err != nil) != r.wantErr {
+			t.Errorf("flexInt(%s) error %v wantErr %v", r.input, err, r.wantErr)
+		}
+		if got != r.want {
+			t.Errorf("flexInt(%s) got %d want %d", r.input, got, r.want)
+		}
+	}
+}
+
+func TestJSONTokenMode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getToken"):
+			_, _ = w.Write([]byte(`{"Authenticated":"True","UserToken":"abc"}`))
+		case strings.HasSuffix(r.URL.Path, "/getTrainSchedule"):
+			http.ServeFile(w, r, "testdata/getTrainSchedule1.json")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, "username", "pa$$word")
+	c.SetAuthMode(AuthToken)
+	got, err := c.StationData(context.Background(), "SE")
+	if err != nil {
+		t.Fatalf("StationData() unexpected error: %v", err)
+	}
+	if got.ID != "SE" || len(got.Departures) != 2 {
+		t.Errorf("StationData() got %s with %d departures, want SE with 2", got.ID, len(got.Departures))
+	}
+}